@@ -0,0 +1,147 @@
+package apperror
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Code adalah kode error yang stabil dan bisa dibaca mesin
+type Code string
+
+const (
+	CodeInternal        Code = "internal_error"
+	CodeBadRequest      Code = "bad_request"
+	CodeInvalidJSON     Code = "invalid_json"
+	CodeInvalidID       Code = "invalid_id"
+	CodeValidation      Code = "validation_failed"
+	CodeUnauthorized    Code = "unauthorized"
+	CodeMissingToken    Code = "missing_token"
+	CodeInvalidToken    Code = "invalid_token"
+	CodeSessionRevoked  Code = "session_revoked"
+	CodeForbidden       Code = "forbidden"
+	CodeNotFound        Code = "not_found"
+	CodeRouteNotFound   Code = "route_not_found"
+	CodeProjectNotFound Code = "project_not_found"
+	CodeSprintNotFound  Code = "sprint_not_found"
+	CodeTaskNotFound    Code = "task_not_found"
+	CodeUserNotFound    Code = "user_not_found"
+	CodeConflict        Code = "conflict"
+	CodeUsernameTaken   Code = "username_taken"
+	CodeEmailTaken      Code = "email_taken"
+
+	CodeInvalidSprintTransition Code = "invalid_sprint_transition"
+	CodeActiveSprintLimit       Code = "active_sprint_limit_reached"
+	CodeInvalidResetToken       Code = "invalid_reset_token"
+	CodeSprintNotPlanned        Code = "sprint_not_planned"
+	CodeSprintCompleted         Code = "sprint_already_completed"
+	CodeTaskInBacklog           Code = "task_already_in_backlog"
+	CodeInvalidCredentials      Code = "invalid_credentials"
+	CodeTooManyLoginAttempts    Code = "too_many_login_attempts"
+	CodeAccountLocked           Code = "account_locked"
+	CodeInvalidTwoFactorCode    Code = "invalid_two_factor_code"
+	CodeTwoFactorRequired       Code = "two_factor_required"
+	CodeTwoFactorEnabled        Code = "two_factor_already_enabled"
+	CodeTwoFactorNotEnabled     Code = "two_factor_not_enabled"
+	CodeTwoFactorRequiredBy     Code = "two_factor_required_by_project"
+	CodeMembersWithoutTwoFactor Code = "project_members_without_two_factor"
+	CodeOIDCProviderNotFound    Code = "oidc_provider_not_found"
+	CodeOIDCLoginFailed         Code = "oidc_login_failed"
+	CodeOIDCAccountNotLinked    Code = "oidc_account_not_linked"
+	CodeIdentityLinked          Code = "identity_already_linked"
+	CodeLastLoginMethod         Code = "last_login_method"
+	CodeInsufficientScope       Code = "insufficient_scope"
+	CodeAccountDisabled         Code = "account_disabled"
+	CodeCannotModifySelf        Code = "cannot_modify_self"
+	CodeUserOwnsProjects        Code = "user_owns_projects"
+	CodeProjectOwner            Code = "cannot_remove_project_owner"
+	CodeOrganizationNotFound    Code = "organization_not_found"
+	CodeLastOrganizationAdmin   Code = "last_organization_admin"
+	CodeInvalidInvitation       Code = "invalid_invitation"
+	CodeAlreadyMember           Code = "already_member"
+	CodeAlreadyParticipant      Code = "already_participant"
+	CodeProjectArchived         Code = "project_archived"
+	CodeSprintArchived          Code = "sprint_archived"
+	CodeSprintActive            Code = "sprint_active"
+	CodeEmailNotVerified        Code = "email_not_verified"
+	CodeEmailAlreadyVerified    Code = "email_already_verified"
+	CodeInvalidVerifyToken      Code = "invalid_verification_token"
+)
+
+// FieldError menjelaskan kesalahan pada satu field request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error adalah error aplikasi yang dirender menjadi response problem+json.
+// Cause disimpan untuk log dan tidak pernah dikirim ke client.
+type Error struct {
+	Status  int
+	Code    Code
+	Details map[string]interface{}
+	Fields  []FieldError
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Cause)
+	}
+	return string(e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithDetails menambahkan data terstruktur yang ikut dikirim ke client
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// New membuat error dengan status dan kode tertentu
+func New(status int, code Code) *Error {
+	return &Error{Status: status, Code: code}
+}
+
+func BadRequest(code Code) *Error {
+	return New(http.StatusBadRequest, code)
+}
+
+func Unauthorized(code Code) *Error {
+	return New(http.StatusUnauthorized, code)
+}
+
+func Forbidden(code Code) *Error {
+	return New(http.StatusForbidden, code)
+}
+
+func NotFound(code Code) *Error {
+	return New(http.StatusNotFound, code)
+}
+
+func TooManyRequests(code Code) *Error {
+	return New(http.StatusTooManyRequests, code)
+}
+
+func Conflict(code Code) *Error {
+	return New(http.StatusConflict, code)
+}
+
+// InvalidJSON membungkus error binding body request
+func InvalidJSON(cause error) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidJSON, Cause: cause}
+}
+
+// Validation membuat error 400 dengan daftar field yang salah
+func Validation(fields ...FieldError) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Fields: fields}
+}
+
+// Internal membungkus error tak terduga (misalnya error GORM) tanpa membocorkannya ke client
+func Internal(cause error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Cause: cause}
+}
@@ -0,0 +1,79 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// From memetakan error apa pun menjadi *Error. Error yang tidak dikenal
+// menjadi internal_error sehingga pesan mentah (misalnya dari GORM) tidak bocor.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			param := fe.Param()
+			if strings.HasSuffix(fe.Tag(), "field") {
+				// Parameter tag *field berisi nama field Go, tampilkan sebagai nama JSON
+				param = snakeCase(param)
+			}
+			fields = append(fields, FieldError{Field: fe.Field(), Code: fe.Tag(), Param: param})
+		}
+		appErr = Validation(fields...)
+		appErr.Cause = err
+		return appErr
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &timeErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return InvalidJSON(err)
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Cause: err}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &Error{Status: http.StatusConflict, Code: CodeConflict, Cause: err}
+	}
+
+	return Internal(err)
+}
+
+// NotFoundOr dipakai setelah lookup satu record: record yang tidak ada menjadi
+// 404 dengan kode resource, error database lain menjadi internal_error
+func NotFoundOr(err error, code Code) *Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Error{Status: http.StatusNotFound, Code: code, Cause: err}
+	}
+	return Internal(err)
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,253 @@
+package apperror
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage dipakai jika Accept-Language kosong atau tidak didukung
+const DefaultLanguage = "en"
+
+var supported = []language.Tag{language.English, language.Indonesian}
+
+var matcher = language.NewMatcher(supported)
+
+var catalogs = map[string]map[Code]string{
+	"en": {
+		CodeInternal:        "An unexpected error occurred",
+		CodeBadRequest:      "The request could not be processed",
+		CodeInvalidJSON:     "The request body is not valid JSON or has the wrong types",
+		CodeInvalidID:       "The ID in the URL is not valid",
+		CodeValidation:      "One or more fields are invalid",
+		CodeUnauthorized:    "Authentication is required",
+		CodeMissingToken:    "Missing authentication token",
+		CodeInvalidToken:    "Invalid or expired authentication token",
+		CodeSessionRevoked:  "This session has been revoked, please log in again",
+		CodeForbidden:       "You are not allowed to perform this action",
+		CodeNotFound:        "Resource not found",
+		CodeRouteNotFound:   "Endpoint not found",
+		CodeProjectNotFound: "Project not found",
+		CodeSprintNotFound:  "Sprint not found",
+		CodeTaskNotFound:    "Task not found",
+		CodeUserNotFound:    "User not found",
+		CodeConflict:        "The resource already exists",
+		CodeUsernameTaken:   "Username already exists",
+		CodeEmailTaken:      "Email is already used by another account",
+
+		CodeInvalidSprintTransition: "The sprint cannot move to the requested status",
+		CodeActiveSprintLimit:       "The project already has the maximum number of active sprints",
+		CodeInvalidResetToken:       "The password reset link is invalid, expired or already used",
+		CodeSprintNotPlanned:        "Tasks can only be moved in or out of a planned sprint",
+		CodeTaskInBacklog:           "The task is already in the project backlog",
+		CodeSprintCompleted:         "The sprint is already completed and can no longer be planned",
+		CodeInvalidCredentials:      "Invalid username or password",
+		CodeTooManyLoginAttempts:    "Too many failed login attempts, please wait before trying again",
+		CodeAccountLocked:           "The account is temporarily locked after too many failed login attempts",
+		CodeInvalidTwoFactorCode:    "The two-factor code is invalid or has already been used",
+		CodeTwoFactorRequired:       "You must enable two-factor authentication first",
+		CodeTwoFactorEnabled:        "Two-factor authentication is already enabled",
+		CodeTwoFactorNotEnabled:     "Two-factor authentication is not enabled or not set up yet",
+		CodeTwoFactorRequiredBy:     "Two-factor authentication is required by one of your projects",
+		CodeMembersWithoutTwoFactor: "Some project participants have not enabled two-factor authentication",
+		CodeOIDCProviderNotFound:    "Single sign-on provider not found",
+		CodeOIDCLoginFailed:         "Single sign-on login failed or expired, please try again",
+		CodeOIDCAccountNotLinked:    "This identity is not linked to any account",
+		CodeIdentityLinked:          "This identity is already linked to another account",
+		CodeLastLoginMethod:         "Cannot remove the only way to sign in to this account",
+		CodeInsufficientScope:       "The access token does not have the scope required for this request",
+		CodeAccountDisabled:         "This account has been disabled by an administrator",
+		CodeCannotModifySelf:        "Administrators cannot disable, delete or demote their own account",
+		CodeUserOwnsProjects:        "Transfer ownership of the user's projects before deleting the user",
+		CodeProjectOwner:            "The project owner cannot be removed from the project",
+		CodeOrganizationNotFound:    "Organization not found",
+		CodeLastOrganizationAdmin:   "An organization must keep at least one admin",
+		CodeInvalidInvitation:       "The invitation is invalid, expired or addressed to another email",
+		CodeAlreadyMember:           "The user is already a member of this organization",
+		CodeAlreadyParticipant:      "The user is already a participant of this project",
+		CodeProjectArchived:         "The project is archived and read-only, unarchive it first",
+		CodeSprintArchived:          "The sprint is archived and read-only, unarchive it first",
+		CodeSprintActive:            "An active sprint cannot be archived or deleted, complete it first",
+		CodeEmailNotVerified:        "Verify your email address before accepting this invitation",
+		CodeEmailAlreadyVerified:    "The email address is already verified",
+		CodeInvalidVerifyToken:      "The email verification link is invalid, expired or already used",
+	},
+	"id": {
+		CodeInternal:        "Terjadi kesalahan yang tidak terduga",
+		CodeBadRequest:      "Request tidak dapat diproses",
+		CodeInvalidJSON:     "Body request bukan JSON yang valid atau tipenya salah",
+		CodeInvalidID:       "ID pada URL tidak valid",
+		CodeValidation:      "Satu atau lebih field tidak valid",
+		CodeUnauthorized:    "Autentikasi diperlukan",
+		CodeMissingToken:    "Token autentikasi tidak ada",
+		CodeInvalidToken:    "Token autentikasi tidak valid atau kedaluwarsa",
+		CodeSessionRevoked:  "Sesi ini sudah dicabut, silakan login kembali",
+		CodeForbidden:       "Anda tidak diizinkan melakukan aksi ini",
+		CodeNotFound:        "Data tidak ditemukan",
+		CodeRouteNotFound:   "Endpoint tidak ditemukan",
+		CodeProjectNotFound: "Project tidak ditemukan",
+		CodeSprintNotFound:  "Sprint tidak ditemukan",
+		CodeTaskNotFound:    "Task tidak ditemukan",
+		CodeUserNotFound:    "User tidak ditemukan",
+		CodeConflict:        "Data sudah ada",
+		CodeUsernameTaken:   "Username sudah digunakan",
+		CodeEmailTaken:      "Email sudah digunakan akun lain",
+
+		CodeInvalidSprintTransition: "Sprint tidak dapat berpindah ke status yang diminta",
+		CodeActiveSprintLimit:       "Project sudah mencapai batas jumlah sprint aktif",
+		CodeInvalidResetToken:       "Link reset password tidak valid, kedaluwarsa atau sudah dipakai",
+		CodeSprintNotPlanned:        "Task hanya dapat dipindah masuk atau keluar dari sprint yang masih planned",
+		CodeTaskInBacklog:           "Task sudah berada di backlog project",
+		CodeSprintCompleted:         "Sprint sudah selesai dan tidak dapat direncanakan lagi",
+		CodeInvalidCredentials:      "Username atau password salah",
+		CodeTooManyLoginAttempts:    "Terlalu banyak login gagal, tunggu sebentar sebelum mencoba lagi",
+		CodeAccountLocked:           "Akun dikunci sementara karena terlalu banyak login gagal",
+		CodeInvalidTwoFactorCode:    "Kode two-factor tidak valid atau sudah dipakai",
+		CodeTwoFactorRequired:       "Anda harus mengaktifkan two-factor authentication terlebih dahulu",
+		CodeTwoFactorEnabled:        "Two-factor authentication sudah aktif",
+		CodeTwoFactorNotEnabled:     "Two-factor authentication belum aktif atau belum di-setup",
+		CodeTwoFactorRequiredBy:     "Two-factor authentication diwajibkan oleh salah satu project Anda",
+		CodeMembersWithoutTwoFactor: "Sebagian participant project belum mengaktifkan two-factor authentication",
+		CodeOIDCProviderNotFound:    "Provider single sign-on tidak ditemukan",
+		CodeOIDCLoginFailed:         "Login single sign-on gagal atau kedaluwarsa, silakan coba lagi",
+		CodeOIDCAccountNotLinked:    "Identity ini belum tertaut ke akun mana pun",
+		CodeIdentityLinked:          "Identity ini sudah tertaut ke akun lain",
+		CodeLastLoginMethod:         "Tidak bisa menghapus satu-satunya cara login ke akun ini",
+		CodeInsufficientScope:       "Token akses tidak memiliki scope yang dibutuhkan request ini",
+		CodeAccountDisabled:         "Akun ini dinonaktifkan oleh administrator",
+		CodeCannotModifySelf:        "Administrator tidak bisa menonaktifkan, menghapus atau mencabut status admin akunnya sendiri",
+		CodeUserOwnsProjects:        "Pindahkan kepemilikan project user ini sebelum menghapusnya",
+		CodeProjectOwner:            "Pemilik project tidak bisa dikeluarkan dari project",
+		CodeOrganizationNotFound:    "Organisasi tidak ditemukan",
+		CodeLastOrganizationAdmin:   "Organisasi harus tetap memiliki minimal satu admin",
+		CodeInvalidInvitation:       "Undangan tidak valid, kedaluwarsa atau ditujukan ke email lain",
+		CodeAlreadyMember:           "User sudah menjadi anggota organisasi ini",
+		CodeAlreadyParticipant:      "User sudah menjadi participant project ini",
+		CodeProjectArchived:         "Project sudah diarsipkan dan hanya bisa dibaca, batalkan arsip terlebih dahulu",
+		CodeSprintArchived:          "Sprint sudah diarsipkan dan hanya bisa dibaca, batalkan arsip terlebih dahulu",
+		CodeSprintActive:            "Sprint yang sedang aktif tidak bisa diarsipkan atau dihapus, selesaikan terlebih dahulu",
+		CodeEmailNotVerified:        "Verifikasi alamat email Anda sebelum menerima undangan ini",
+		CodeEmailAlreadyVerified:    "Alamat email sudah terverifikasi",
+		CodeInvalidVerifyToken:      "Link verifikasi email tidak valid, kedaluwarsa atau sudah dipakai",
+	},
+}
+
+// fieldCatalogs berisi pesan untuk FieldError.Code; {param} diganti dengan FieldError.Param
+var fieldCatalogs = map[string]map[string]string{
+	"en": {
+		"required":        "This field is required",
+		"invalid":         "This field is invalid",
+		"min":             "Must be at least {param} characters",
+		"max":             "Must be at most {param} characters",
+		"gt":              "Must be greater than {param}",
+		"gte":             "Must be greater than or equal to {param}",
+		"lte":             "Must be less than or equal to {param}",
+		"email":           "Must be a valid email address",
+		"url":             "Must be a valid URL",
+		"nefield":         "Must be different from {param}",
+		"currentpassword": "Does not match your current password",
+		"unique":          "Must not contain duplicate values",
+		"gtfield":         "Must be after {param}",
+		"gtefield":        "Must not be before {param}",
+		"oneof":           "Must be one of: {param}",
+		"taskstatus":      "Must be one of: todo, in_progress, done",
+		"sprintstatus":    "Must be one of: planned, active, completed",
+		"estimationtype":  "Must be one of: hour, story_point",
+		"carryovertarget": "Must be a planned or active sprint in the same project",
+		"sprintproject":   "Sprint must belong to the given project",
+		"backlogtask":     "Every task must be in this project's backlog",
+		"participant":     "User must be a participant of the project",
+		"maxrange":        "Date range must not exceed {param} days",
+		"passwordupper":   "Must contain an uppercase letter",
+		"passwordlower":   "Must contain a lowercase letter",
+		"passworddigit":   "Must contain a digit",
+		"passwordsymbol":  "Must contain a symbol",
+		"breached":        "This password has appeared in a data breach, choose another one",
+		"twofactor":       "User must have two-factor authentication enabled",
+		"userdisabled":    "User has been disabled",
+		"orgmember":       "User must be a member of the project's organization",
+		"orgrole":         "Must be one of: admin, member",
+		"projectrole":     "Must be one of: maintainer, member",
+		"singleuse":       "Email invitations can only be used once",
+		"twofactorcode":   "The two-factor code is invalid",
+		"tokenscope":      "Must be one of: read, tasks:write, admin",
+	},
+	"id": {
+		"required":        "Field ini wajib diisi",
+		"invalid":         "Field ini tidak valid",
+		"min":             "Minimal {param} karakter",
+		"max":             "Maksimal {param} karakter",
+		"gt":              "Harus lebih besar dari {param}",
+		"gte":             "Harus lebih besar atau sama dengan {param}",
+		"lte":             "Harus lebih kecil atau sama dengan {param}",
+		"email":           "Harus berupa alamat email yang valid",
+		"url":             "Harus berupa URL yang valid",
+		"nefield":         "Harus berbeda dari {param}",
+		"currentpassword": "Tidak sesuai dengan password saat ini",
+		"unique":          "Tidak boleh berisi nilai duplikat",
+		"gtfield":         "Harus setelah {param}",
+		"gtefield":        "Tidak boleh sebelum {param}",
+		"oneof":           "Harus salah satu dari: {param}",
+		"taskstatus":      "Harus salah satu dari: todo, in_progress, done",
+		"sprintstatus":    "Harus salah satu dari: planned, active, completed",
+		"estimationtype":  "Harus salah satu dari: hour, story_point",
+		"carryovertarget": "Harus sprint planned atau active di project yang sama",
+		"sprintproject":   "Sprint harus milik project yang diberikan",
+		"backlogtask":     "Semua task harus berada di backlog project ini",
+		"participant":     "User harus menjadi participant project",
+		"maxrange":        "Rentang tanggal maksimal {param} hari",
+		"passwordupper":   "Harus mengandung huruf besar",
+		"passwordlower":   "Harus mengandung huruf kecil",
+		"passworddigit":   "Harus mengandung angka",
+		"passwordsymbol":  "Harus mengandung simbol",
+		"breached":        "Password ini pernah bocor di data breach, pilih password lain",
+		"twofactor":       "User harus mengaktifkan two-factor authentication",
+		"userdisabled":    "User sudah dinonaktifkan",
+		"orgmember":       "User harus menjadi anggota organisasi project",
+		"orgrole":         "Harus salah satu dari: admin, member",
+		"projectrole":     "Harus salah satu dari: maintainer, member",
+		"singleuse":       "Undangan email hanya bisa dipakai sekali",
+		"twofactorcode":   "Kode two-factor tidak valid",
+		"tokenscope":      "Harus salah satu dari: read, tasks:write, admin",
+	},
+}
+
+// Language memilih bahasa katalog berdasarkan header Accept-Language
+func Language(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLanguage
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLanguage
+	}
+	base, _ := supported[index].Base()
+	return base.String()
+}
+
+// Message mengembalikan pesan untuk kode error dalam bahasa yang diminta
+func Message(lang string, code Code) string {
+	if msg, ok := catalogs[lang][code]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[DefaultLanguage][code]; ok {
+		return msg
+	}
+	return string(code)
+}
+
+// FieldMessage mengembalikan pesan untuk kode error field dalam bahasa yang diminta
+func FieldMessage(lang, code, param string) string {
+	msg, ok := fieldCatalogs[lang][code]
+	if !ok {
+		msg, ok = fieldCatalogs[DefaultLanguage][code]
+	}
+	if !ok {
+		msg = fieldCatalogs[lang]["invalid"]
+		if msg == "" {
+			msg = fieldCatalogs[DefaultLanguage]["invalid"]
+		}
+	}
+	return strings.ReplaceAll(msg, "{param}", param)
+}
@@ -0,0 +1,56 @@
+package apperror
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType adalah media type RFC 7807
+const ContentType = "application/problem+json"
+
+// Problem adalah body response error mengikuti RFC 7807 (problem+json)
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      Code                   `json:"code"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Errors    []FieldError           `json:"errors,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// NewProblem menerjemahkan Error menjadi Problem dalam bahasa tertentu
+func NewProblem(err *Error, lang string) Problem {
+	fields := make([]FieldError, len(err.Fields))
+	for i, f := range err.Fields {
+		if f.Message == "" {
+			f.Message = FieldMessage(lang, f.Code, f.Param)
+		}
+		fields[i] = f
+	}
+
+	return Problem{
+		Type:    "/errors/" + string(err.Code),
+		Title:   http.StatusText(err.Status),
+		Status:  err.Status,
+		Detail:  Message(lang, err.Code),
+		Code:    err.Code,
+		Details: err.Details,
+		Errors:  fields,
+	}
+}
+
+// Render menulis Error sebagai problem+json dan menghentikan chain handler
+func Render(c *gin.Context, err *Error) {
+	lang := Language(c.GetHeader("Accept-Language"))
+	problem := NewProblem(err, lang)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString("request_id")
+
+	c.Header("Content-Language", lang)
+	c.Abort()
+	c.Render(err.Status, problemRender{problem})
+}
@@ -0,0 +1,23 @@
+package apperror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problemRender menulis JSON dengan Content-Type application/problem+json
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{ContentType + "; charset=utf-8"}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/models"
+	"kanban/passwords"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// runCommand menjalankan subcommand CLI, misalnya `kanban create-admin -username alice`,
+// dan mengembalikan exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "create-admin":
+		if err := createAdmin(args[1:], os.Stdin); err != nil {
+			fmt.Fprintln(os.Stderr, "create-admin:", err)
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "perintah tidak dikenal: %s\n\nPerintah yang tersedia:\n  create-admin  buat administrator pertama atau jadikan user yang ada administrator\n", args[0])
+		return 2
+	}
+}
+
+// createAdmin membuat user administrator, atau memberi status admin (dan mengaktifkan
+// kembali) user yang sudah ada. Password user baru dibaca dari KANBAN_ADMIN_PASSWORD
+// atau baris pertama stdin, agar tidak tercatat di riwayat shell.
+func createAdmin(args []string, stdin io.Reader) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := flags.String("username", "", "username administrator (wajib)")
+	email := flags.String("email", "", "email administrator untuk user baru")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		flags.Usage()
+		return errors.New("-username wajib diisi")
+	}
+
+	config.ConnectDB()
+	defer config.CloseDB()
+
+	var user models.User
+	err := config.DB.Where("username = ?", *username).First(&user).Error
+	if err == nil {
+		if err := config.DB.Model(&user).Updates(map[string]interface{}{"is_admin": true, "disabled_at": nil}).Error; err != nil {
+			return err
+		}
+		fmt.Printf("User %s (id %d) sekarang administrator\n", user.Username, user.ID)
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	password := os.Getenv("KANBAN_ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	violations, err := passwords.PolicyFromEnv().Check(password)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = apperror.FieldMessage(apperror.DefaultLanguage, v.Code, v.Param)
+		}
+		return fmt.Errorf("password ditolak: %s", strings.Join(messages, "; "))
+	}
+
+	hashed, err := passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	user = models.User{Username: *username, Password: hashed, Email: *email, IsAdmin: true}
+	if err := config.DB.Create(&user).Error; err != nil {
+		return err
+	}
+	fmt.Printf("Administrator %s dibuat dengan id %d\n", user.Username, user.ID)
+	return nil
+}
@@ -2,39 +2,226 @@ package config
 
 import (
 	"fmt"
+	"kanban/logger"
+	"kanban/metrics"
 	"kanban/models"
-	"log"
+	"kanban/tracing"
+	"log/slog"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
 )
+
 var DB *gorm.DB
 
-func ConnectDB() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("⚠️  Gagal memuat file .env, lanjutkan dengan environment default")
+// migrationState menyimpan hasil pemeriksaan migrasi agar readiness probe tidak
+// memeriksa skema database di setiap request
+var migrationState struct {
+	sync.Mutex
+	checkedAt time.Time
+	pending   []string
+}
+
+// Models adalah daftar model yang dimigrasikan oleh AutoMigrate
+var Models = []interface{}{
+	&models.Project{},
+	&models.ProjectUser{},
+	&models.Task{},
+	&models.User{},
+	&models.Sprint{},
+	&models.SprintSnapshot{},
+	&models.TaskCarryOver{},
+	&models.SprintCapacity{},
+	&models.TaskStatusTransition{},
+	&models.SprintScopeChange{},
+	&models.PasswordResetToken{},
+	&models.EmailVerificationToken{},
+	&models.LoginThrottle{},
+	&models.RecoveryCode{},
+	&models.UserIdentity{},
+	&models.OIDCLoginState{},
+	&models.PersonalAccessToken{},
+	&models.Organization{},
+	&models.OrganizationMember{},
+	&models.OrganizationInvitation{},
+	&models.ProjectInvitation{},
+}
+
+// LoadEnv memuat file .env jika ada
+func LoadEnv() {
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("Gagal memuat file .env, lanjutkan dengan environment default")
 	}
+}
+
+func ConnectDB() {
 
 	host := os.Getenv("DB_HOST")
 	port := os.Getenv("DB_PORT")
 	user := os.Getenv("DB_USER")
 	password := os.Getenv("DB_PASSWORD")
 	dbname := os.Getenv("DB_NAME")
-	
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		user, password, host, port, dbname,
 	)
-	database, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	database, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger:         logger.NewGormLogger(GetEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond)),
+		TranslateError: true,
+	})
+	if err != nil {
+		slog.Error("Gagal konek database", "error", err)
+		os.Exit(1)
+	}
+
+	sqlDB, err := database.DB()
 	if err != nil {
-		log.Fatal("Gagal konek database:", err)
+		slog.Error("Gagal mengambil koneksi database", "error", err)
+		os.Exit(1)
+	}
+	sqlDB.SetMaxOpenConns(GetEnvInt("DB_MAX_OPEN_CONNS", 25))
+	sqlDB.SetMaxIdleConns(GetEnvInt("DB_MAX_IDLE_CONNS", 10))
+	sqlDB.SetConnMaxLifetime(GetEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute))
+	sqlDB.SetConnMaxIdleTime(GetEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute))
+
+	if err := database.Use(metrics.GormPlugin{}); err != nil {
+		slog.Warn("Gagal memasang plugin metrics GORM", "error", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB, dbname); err != nil {
+		slog.Warn("Gagal mendaftarkan metrics pool database", "error", err)
+	}
+	if tracing.Enabled() {
+		if err := database.Use(otelgorm.NewPlugin(otelgorm.WithDBName(dbname), otelgorm.WithoutMetrics())); err != nil {
+			slog.Warn("Gagal memasang plugin tracing GORM", "error", err)
+		}
+	}
+
+	if err := database.AutoMigrate(Models...); err != nil {
+		slog.Error("Gagal migrasi database", "error", err)
+		os.Exit(1)
+	}
+
+	// Email kosong disimpan sebagai NULL agar tidak bentrok dengan index unique
+	if err := database.Exec("UPDATE users SET email = NULL WHERE email = ''").Error; err != nil {
+		slog.Error("Gagal mengosongkan email kosong", "error", err)
+		os.Exit(1)
+	}
+
+	// Task lama belum punya project_id, isi dari sprint-nya
+	if err := database.Exec("UPDATE tasks JOIN sprints ON sprints.id = tasks.sprint_id SET tasks.project_id = sprints.project_id WHERE tasks.project_id IS NULL OR tasks.project_id = 0").Error; err != nil {
+		slog.Error("Gagal mengisi project_id task lama", "error", err)
+		os.Exit(1)
 	}
 
-	
-	database.AutoMigrate(&models.Project{}, &models.Task{}, &models.User{}, &models.Sprint{})
+	if err := migrateDefaultOrganization(database); err != nil {
+		slog.Error("Gagal memindahkan project lama ke organisasi default", "error", err)
+		os.Exit(1)
+	}
 
+	// Hasil pemeriksaan dipakai readiness probe; jika gagal, probe akan mencoba lagi
+	CheckMigrations(database)
 	DB = database
-}
\ No newline at end of file
+}
+
+// migrateDefaultOrganization memindahkan project lama yang belum punya organisasi ke
+// organisasi "Default". Semua user yang belum tergabung di organisasi mana pun menjadi
+// anggotanya (administrator sistem sebagai admin) agar data lama tetap terlihat seperti sebelumnya.
+// Semua langkah berjalan dalam satu transaksi agar tidak ada organisasi default setengah jadi.
+func migrateDefaultOrganization(database *gorm.DB) error {
+	var orphans int64
+	if err := database.Model(&models.Project{}).Where("organization_id IS NULL").Count(&orphans).Error; err != nil {
+		return err
+	}
+	if orphans == 0 {
+		return nil
+	}
+
+	org := models.Organization{Name: "Default"}
+	err := database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE projects SET organization_id = ? WHERE organization_id IS NULL", org.ID).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role, created_at)
+			SELECT ?, id, CASE WHEN is_admin THEN ? ELSE ? END, NOW() FROM users
+			WHERE deleted_at IS NULL AND id NOT IN (SELECT user_id FROM organization_members)`,
+			org.ID, models.OrgRoleAdmin, models.OrgRoleMember).Error
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Project lama dipindahkan ke organisasi default", "organization_id", org.ID, "projects", orphans)
+	return nil
+}
+
+// CloseDB menutup pool koneksi database
+func CloseDB() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// PendingMigrations mengembalikan daftar tabel/kolom model yang belum ada di database
+func PendingMigrations(db *gorm.DB) ([]string, error) {
+	var pending []string
+	migrator := db.Migrator()
+	for _, model := range Models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+		if !migrator.HasTable(model) {
+			pending = append(pending, table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(model, field.DBName) {
+				pending = append(pending, table+"."+field.DBName)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// CheckMigrations memeriksa migrasi yang tertunda lalu menyimpan hasilnya untuk
+// MigrationStatus. Jika pemeriksaan gagal, hasil lama tidak diubah agar bisa dicoba lagi.
+func CheckMigrations(db *gorm.DB) error {
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		slog.Error("Gagal memeriksa migrasi database", "error", err)
+		return err
+	}
+
+	migrationState.Lock()
+	defer migrationState.Unlock()
+	migrationState.checkedAt = time.Now()
+	migrationState.pending = pending
+	return nil
+}
+
+// MigrationStatus mengembalikan hasil CheckMigrations terakhir yang berhasil. checked
+// false berarti migrasi belum pernah berhasil diperiksa atau hasilnya sudah lebih lama
+// dari MIGRATION_CHECK_INTERVAL, sehingga perlu diperiksa ulang.
+func MigrationStatus() (pending []string, checked bool) {
+	migrationState.Lock()
+	defer migrationState.Unlock()
+	fresh := !migrationState.checkedAt.IsZero() &&
+		time.Since(migrationState.checkedAt) < GetEnvDuration("MIGRATION_CHECK_INTERVAL", time.Minute)
+	return migrationState.pending, fresh
+}
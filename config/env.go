@@ -0,0 +1,71 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnv mengembalikan nilai environment variable atau fallback jika kosong
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvInt membaca environment variable sebagai integer
+func GetEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("environment variable tidak valid, memakai default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// GetEnvDuration membaca environment variable sebagai durasi, contoh: "15s", "1m"
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("environment variable tidak valid, memakai default", "key", key, "value", value, "default", fallback.String())
+		return fallback
+	}
+	return parsed
+}
+
+// GetEnvBool membaca environment variable sebagai boolean, contoh: "true", "1", "false"
+func GetEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("environment variable tidak valid, memakai default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// GetEnvList membaca environment variable berisi daftar yang dipisah koma, contoh: "10.0.0.1,10.0.0.0/8".
+// Entri kosong diabaikan; variable kosong menghasilkan nil.
+func GetEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
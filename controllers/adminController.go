@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"fmt"
+	"kanban/apperror"
+	"kanban/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAdminUsers mendapatkan semua user, bisa dicari dengan q dan difilter dengan
+// disabled=true|false, diurutkan berdasarkan ID dan dipaginasi dengan page/per_page
+func GetAdminUsers(c *gin.Context) {
+	var query models.AdminUserQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PerPage == 0 {
+		query.PerPage = 50
+	}
+
+	scope := db(c).Model(&models.User{})
+	if query.Q != "" {
+		like := "%" + query.Q + "%"
+		scope = scope.Where("username LIKE ? OR email LIKE ? OR display_name LIKE ?", like, like, like)
+	}
+	if query.Disabled != nil {
+		if *query.Disabled {
+			scope = scope.Where("disabled_at IS NOT NULL")
+		} else {
+			scope = scope.Where("disabled_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	var users []models.User
+	if err := scope.Order("id").Offset((query.Page - 1) * query.PerPage).Limit(query.PerPage).Find(&users).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": users, "total": total, "page": query.Page, "per_page": query.PerPage})
+}
+
+// DisableUser menonaktifkan user: login ditolak dan semua sesi JWT serta token akses
+// personal-nya langsung berhenti berlaku
+func DisableUser(c *gin.Context) {
+	user, err := updateAdminTarget(c, func(tx *gorm.DB, user *models.User) error {
+		if user.DisabledAt != nil {
+			return nil
+		}
+		return tx.Model(user).Updates(map[string]interface{}{
+			"disabled_at":   time.Now(),
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// EnableUser mengaktifkan kembali user yang dinonaktifkan. Sesi lama tetap tidak
+// berlaku sehingga user harus login ulang.
+func EnableUser(c *gin.Context) {
+	user, err := updateAdminTarget(c, func(tx *gorm.DB, user *models.User) error {
+		return tx.Model(user).Update("disabled_at", nil).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// SetUserAdmin memberi atau mencabut status administrator sistem
+func SetUserAdmin(c *gin.Context) {
+	var input models.SetAdminRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := updateAdminTarget(c, func(tx *gorm.DB, user *models.User) error {
+		return tx.Model(user).Update("is_admin", *input.Admin).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// DeleteUser menghapus user (soft delete). User yang masih memiliki project harus
+// dipindahkan dulu kepemilikannya; keanggotaan project dan organisasi, assignment task,
+// identity SSO, token akses dan recovery code-nya ikut dihapus.
+func DeleteUser(c *gin.Context) {
+	_, err := updateAdminTarget(c, func(tx *gorm.DB, user *models.User) error {
+		var projectIDs []uint
+		if err := tx.Model(&models.Project{}).Where("owner_id = ?", user.ID).Order("id").Pluck("id", &projectIDs).Error; err != nil {
+			return err
+		}
+		if len(projectIDs) > 0 {
+			return apperror.Conflict(apperror.CodeUserOwnsProjects).
+				WithDetails(map[string]interface{}{"project_ids": projectIDs})
+		}
+
+		if err := tx.Model(user).Association("Projects").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Task{}).Where("assign_to = ?", user.ID).Update("assign_to", nil).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.OrganizationMember{}, &models.UserIdentity{}, &models.PersonalAccessToken{}, &models.RecoveryCode{}, &models.PasswordResetToken{}} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(user).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// updateAdminTarget mengunci user dari parameter :id lalu menjalankan update di dalam
+// transaksi. Admin tidak bisa mengubah akunnya sendiri, sehingga selalu tersisa
+// minimal satu administrator aktif.
+func updateAdminTarget(c *gin.Context, update func(tx *gorm.DB, user *models.User) error) (models.User, error) {
+	var id uint
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &id); err != nil {
+		return models.User{}, apperror.BadRequest(apperror.CodeInvalidID)
+	}
+	if id == c.GetUint("user_id") {
+		return models.User{}, apperror.Conflict(apperror.CodeCannotModifySelf)
+	}
+
+	var user models.User
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = lockUser(tx, id); err != nil {
+			return err
+		}
+		if err := update(tx, &user); err != nil {
+			return err
+		}
+		return tx.First(&user, id).Error
+	})
+	return user, err
+}
+
+// UnlockUser menghapus lockout dan hitungan login gagal sebuah akun
+func UnlockUser(c *gin.Context) {
+	var user models.User
+	if err := db(c).First(&user, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeUserNotFound))
+		return
+	}
+
+	if err := clearAccountThrottle(db(c), user.Username); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// TransferProjectOwner memindahkan kepemilikan project ke user lain. Pemilik baru
+// otomatis menjadi participant dan harus anggota organisasi project serta memenuhi
+// syarat 2FA project.
+func TransferProjectOwner(c *gin.Context) {
+	var input models.TransferProjectOwnerRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	var project models.Project
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&project, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+
+		var user models.User
+		if err := tx.First(&user, input.UserID).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeUserNotFound)
+		}
+		if user.DisabledAt != nil {
+			return apperror.Validation(apperror.FieldError{Field: "user_id", Code: "userdisabled"})
+		}
+		if err := requireOrgMembers(tx, project.OrganizationID, "user_id", user.ID); err != nil {
+			return err
+		}
+		if project.RequireTwoFactor && !user.TOTPEnabled {
+			return apperror.Validation(apperror.FieldError{Field: "user_id", Code: "twofactor"})
+		}
+
+		if err := tx.Model(&project).Association("UserParticipants").Append(&user); err != nil {
+			return err
+		}
+		return tx.Model(&project).Update("owner_id", user.ID).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db(c).Preload("UserParticipants").First(&project, project.ID)
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// GetAdminStats mendapatkan ringkasan jumlah data di seluruh sistem
+func GetAdminStats(c *gin.Context) {
+	var users struct {
+		Total     int64 `json:"total"`
+		Admins    int64 `json:"admins"`
+		Disabled  int64 `json:"disabled"`
+		TwoFactor int64 `json:"two_factor_enabled"`
+	}
+	var projects, activeTokens int64
+
+	counts := []struct {
+		model interface{}
+		where string
+		dest  *int64
+	}{
+		{&models.User{}, "", &users.Total},
+		{&models.User{}, "is_admin = true", &users.Admins},
+		{&models.User{}, "disabled_at IS NOT NULL", &users.Disabled},
+		{&models.User{}, "totp_enabled = true", &users.TwoFactor},
+		{&models.Project{}, "", &projects},
+		{&models.PersonalAccessToken{}, "revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())", &activeTokens},
+	}
+	for _, count := range counts {
+		scope := db(c).Model(count.model)
+		if count.where != "" {
+			scope = scope.Where(count.where)
+		}
+		if err := scope.Count(count.dest).Error; err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
+	tasks, err := countByStatus(c, &models.Task{}, models.TaskStatuses)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	sprints, err := countByStatus(c, &models.Sprint{}, models.SprintStatuses)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"users":                  users,
+		"projects":               projects,
+		"tasks":                  tasks,
+		"sprints":                sprints,
+		"active_personal_tokens": activeTokens,
+	}})
+}
+
+// countByStatus menghitung record per status; status tanpa record tetap muncul dengan nilai 0
+func countByStatus(c *gin.Context, model interface{}, statuses []string) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := db(c).Model(model).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(statuses))
+	for _, status := range statuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
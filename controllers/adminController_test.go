@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupAdminTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{},
+		&models.PersonalAccessToken{}, &models.UserIdentity{}, &models.RecoveryCode{}, &models.PasswordResetToken{},
+		&models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownAdminTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE password_reset_tokens")
+		config.DB.Exec("TRUNCATE TABLE recovery_codes")
+		config.DB.Exec("TRUNCATE TABLE user_identities")
+		config.DB.Exec("TRUNCATE TABLE personal_access_tokens")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func setupAdminRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/me", middlewares.AuthMiddleware(), GetMe)
+
+	admin := router.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(), middlewares.RequireAdmin())
+	admin.GET("/users", GetAdminUsers)
+	admin.POST("/users/:id/disable", DisableUser)
+	admin.POST("/users/:id/enable", EnableUser)
+	admin.PUT("/users/:id/admin", SetUserAdmin)
+	admin.DELETE("/users/:id", DeleteUser)
+	admin.PUT("/projects/:id/owner", TransferProjectOwner)
+	admin.GET("/stats", GetAdminStats)
+	return router
+}
+
+func adminRequest(router *gin.Engine, method, path, username string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	var user models.User
+	config.DB.Where("username = ?", username).First(&user)
+	token, _ := middlewares.GenerateJWT(user.ID, username, user.TokenVersion)
+
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	return resp, response
+}
+
+func TestAdminRequiresAdminFlag(t *testing.T) {
+	setupAdminTestDB()
+	defer teardownAdminTestDB()
+
+	config.DB.Create(&models.User{Username: "root", Password: "hashed", IsAdmin: true})
+	config.DB.Create(&models.User{Username: "regular", Password: "hashed"})
+	router := setupAdminRouter()
+
+	resp, _ := adminRequest(router, "GET", "/admin/users", "regular", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, response := adminRequest(router, "GET", "/admin/users?q=reg", "root", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(1), response["total"])
+	assert.Equal(t, "regular", response["data"].([]interface{})[0].(map[string]interface{})["username"])
+
+	var regular models.User
+	config.DB.Where("username = ?", "regular").First(&regular)
+	resp, _ = adminRequest(router, "PUT", fmt.Sprintf("/admin/users/%d/admin", regular.ID), "root", map[string]bool{"admin": true})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "GET", "/admin/stats", "regular", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	setupAdminTestDB()
+	defer teardownAdminTestDB()
+
+	root := models.User{Username: "root", Password: "hashed", IsAdmin: true}
+	target := models.User{Username: "target", Password: "hashed"}
+	config.DB.Create(&root)
+	config.DB.Create(&target)
+	router := setupAdminRouter()
+
+	// Admin tidak bisa menonaktifkan dirinya sendiri
+	resp, response := adminRequest(router, "POST", fmt.Sprintf("/admin/users/%d/disable", root.ID), "root", nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "cannot_modify_self", response["code"])
+
+	targetToken, _ := middlewares.GenerateJWT(target.ID, "target", 0)
+
+	resp, response = adminRequest(router, "POST", fmt.Sprintf("/admin/users/%d/disable", target.ID), "root", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotNil(t, response["data"].(map[string]interface{})["disabled_at"])
+
+	// Sesi lama dicabut
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", targetToken)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Token baru pun ditolak selama user nonaktif
+	resp, response = adminRequest(router, "GET", "/me", "target", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "account_disabled", response["code"])
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/admin/users/%d/enable", target.ID), "root", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "GET", "/me", "target", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAdminDeleteUserAndTransferOwnership(t *testing.T) {
+	setupAdminTestDB()
+	defer teardownAdminTestDB()
+
+	root := models.User{Username: "root", Password: "hashed", IsAdmin: true}
+	leaving := models.User{Username: "leaving", Password: "hashed"}
+	config.DB.Create(&root)
+	config.DB.Create(&leaving)
+
+	project := models.Project{Name: "Handover", OwnerID: &leaving.ID, UserParticipants: []models.User{leaving}}
+	config.DB.Create(&project)
+	task := models.Task{Title: "Assigned", Status: models.TaskStatusTodo, ProjectID: project.ID, AssignTo: &leaving.ID}
+	config.DB.Create(&task)
+	router := setupAdminRouter()
+
+	resp, response := adminRequest(router, "DELETE", fmt.Sprintf("/admin/users/%d", leaving.ID), "root", nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "user_owns_projects", response["code"])
+
+	resp, response = adminRequest(router, "PUT", fmt.Sprintf("/admin/projects/%d/owner", project.ID), "root", map[string]uint{"user_id": root.ID})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(root.ID), response["data"].(map[string]interface{})["owner_id"])
+	assert.Len(t, response["data"].(map[string]interface{})["UserParticipants"], 2)
+
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/admin/users/%d", leaving.ID), "root", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var reloaded models.Task
+	config.DB.First(&reloaded, task.ID)
+	assert.Nil(t, reloaded.AssignTo)
+
+	var members int64
+	config.DB.Table("project_users").Where("user_id = ?", leaving.ID).Count(&members)
+	assert.Equal(t, int64(0), members)
+
+	resp, response = adminRequest(router, "GET", "/admin/stats", "root", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	stats := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), stats["users"].(map[string]interface{})["total"])
+	assert.Equal(t, float64(1), stats["projects"])
+	assert.Equal(t, float64(1), stats["tasks"].(map[string]interface{})["todo"])
+}
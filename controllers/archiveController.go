@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArchiveProject mengarsipkan project. Project arsip hilang dari daftar default dan
+// seluruh isinya hanya bisa dibaca sampai arsipnya dibatalkan.
+func ArchiveProject(c *gin.Context) {
+	setProjectArchived(c, true)
+}
+
+// UnarchiveProject membatalkan arsip project
+func UnarchiveProject(c *gin.Context) {
+	setProjectArchived(c, false)
+}
+
+func setProjectArchived(c *gin.Context, archived bool) {
+	var project models.Project
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(tenantProjects(c)).Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+		if err := requireProjectAdmin(c, tx, &project); err != nil {
+			return err
+		}
+		if (project.ArchivedAt != nil) == archived {
+			return nil
+		}
+
+		project.ArchivedAt = nil
+		if archived {
+			now := time.Now()
+			project.ArchivedAt = &now
+		}
+		return tx.Model(&project).Update("archived_at", project.ArchivedAt).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// ArchiveSprint mengarsipkan sprint yang tidak sedang aktif
+func ArchiveSprint(c *gin.Context) {
+	setSprintArchived(c, true)
+}
+
+// UnarchiveSprint membatalkan arsip sprint
+func UnarchiveSprint(c *gin.Context) {
+	setSprintArchived(c, false)
+}
+
+func setSprintArchived(c *gin.Context, archived bool) {
+	var sprint models.Sprint
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockSprint(tx.Scopes(tenantRecords(c, "sprints")), c.Param("id"), &sprint); err != nil {
+			return err
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, nil); err != nil {
+			return err
+		}
+		if (sprint.ArchivedAt != nil) == archived {
+			return nil
+		}
+		if archived && sprint.Status == models.SprintStatusActive {
+			return apperror.Conflict(apperror.CodeSprintActive)
+		}
+
+		sprint.ArchivedAt = nil
+		if archived {
+			now := time.Now()
+			sprint.ArchivedAt = &now
+		}
+		return tx.Model(&sprint).Update("archived_at", sprint.ArchivedAt).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sprint})
+}
+
+// ensureWritable memastikan project, dan sprint jika ada, belum diarsipkan sebelum
+// isinya diubah
+func ensureWritable(tx *gorm.DB, projectID uint, sprintID *uint) error {
+	var project models.Project
+	if err := tx.Select("id", "archived_at").First(&project, projectID).Error; err != nil {
+		return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+	}
+	if err := writableProject(&project); err != nil {
+		return err
+	}
+	if sprintID == nil {
+		return nil
+	}
+
+	var sprint models.Sprint
+	if err := tx.Select("id", "archived_at").First(&sprint, *sprintID).Error; err != nil {
+		return apperror.NotFoundOr(err, apperror.CodeSprintNotFound)
+	}
+	return writableSprint(&sprint)
+}
+
+// writableProject menolak perubahan pada project yang diarsipkan
+func writableProject(project *models.Project) error {
+	if project.ArchivedAt != nil {
+		return apperror.Conflict(apperror.CodeProjectArchived).
+			WithDetails(map[string]interface{}{"project_id": project.ID})
+	}
+	return nil
+}
+
+// writableSprint menolak perubahan pada sprint yang diarsipkan
+func writableSprint(sprint *models.Sprint) error {
+	if sprint.ArchivedAt != nil {
+		return apperror.Conflict(apperror.CodeSprintArchived).
+			WithDetails(map[string]interface{}{"sprint_id": sprint.ID})
+	}
+	return nil
+}
+
+// withoutArchived menyembunyikan record yang diarsipkan kecuali include_archived diminta
+func withoutArchived(table string, query models.ArchiveQuery) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if query.IncludeArchived {
+			return tx
+		}
+		return tx.Where(table + ".archived_at IS NULL")
+	}
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupArchiveTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{},
+		&models.TaskStatusTransition{}, &models.SprintScopeChange{}, &models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownArchiveTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("TRUNCATE TABLE sprint_scope_changes")
+		config.DB.Exec("TRUNCATE TABLE task_status_transitions")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func setupArchiveRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+
+	auth := router.Group("/")
+	auth.Use(middlewares.AuthMiddleware(), middlewares.TenantScope())
+	auth.GET("/projects", GetAllProjects)
+	auth.POST("/projects/:id/archive", ArchiveProject)
+	auth.POST("/projects/:id/unarchive", UnarchiveProject)
+	auth.GET("/projects/:id/sprints", GetSprintsByProject)
+	auth.POST("/sprints/:id/archive", ArchiveSprint)
+	auth.POST("/sprints/:id/unarchive", UnarchiveSprint)
+	auth.POST("/sprints/:id/start", StartSprint)
+	auth.POST("/tasks", CreateTask)
+	auth.PUT("/tasks/:id", UpdateTaskStatus)
+	return router
+}
+
+func TestArchiveProject(t *testing.T) {
+	setupArchiveTestDB()
+	defer teardownArchiveTestDB()
+
+	owner := models.User{Username: "owner", Password: "hashed"}
+	other := models.User{Username: "other", Password: "hashed"}
+	config.DB.Create(&owner)
+	config.DB.Create(&other)
+	org := seedTenant(owner, other)
+	project := models.Project{Name: "Finished", OrganizationID: &org.ID, OwnerID: &owner.ID}
+	config.DB.Create(&project)
+	config.DB.Create(&models.Project{Name: "Ongoing", OrganizationID: &org.ID})
+	task := models.Task{Title: "Done", Status: models.TaskStatusDone, ProjectID: project.ID}
+	config.DB.Create(&task)
+	router := setupArchiveRouter()
+
+	resp, _ := adminRequest(router, "POST", fmt.Sprintf("/projects/%d/archive", project.ID), "other", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, response := adminRequest(router, "POST", fmt.Sprintf("/projects/%d/archive", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotNil(t, response["data"].(map[string]interface{})["archived_at"])
+
+	resp, response = adminRequest(router, "GET", "/projects", "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+
+	resp, response = adminRequest(router, "GET", "/projects?include_archived=true", "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 2)
+
+	// Isi project arsip hanya bisa dibaca
+	resp, response = adminRequest(router, "PUT", fmt.Sprintf("/tasks/%d", task.ID), "owner", map[string]string{"status": "todo"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "project_archived", response["code"])
+
+	resp, _ = adminRequest(router, "POST", "/tasks", "owner", map[string]interface{}{"title": "New", "status": "todo", "project_id": project.ID})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/projects/%d/unarchive", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "PUT", fmt.Sprintf("/tasks/%d", task.ID), "owner", map[string]string{"status": "todo"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestArchiveSprint(t *testing.T) {
+	setupArchiveTestDB()
+	defer teardownArchiveTestDB()
+
+	owner := models.User{Username: "owner", Password: "hashed"}
+	config.DB.Create(&owner)
+	org := seedTenant(owner)
+	project := models.Project{Name: "Rocket", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	active := models.Sprint{ProjectID: project.ID, Name: "Active", EstimationType: models.EstimationTypeHour, Status: models.SprintStatusActive}
+	planned := models.Sprint{ProjectID: project.ID, Name: "Planned", EstimationType: models.EstimationTypeHour, Status: models.SprintStatusPlanned}
+	config.DB.Create(&active)
+	config.DB.Create(&planned)
+	router := setupArchiveRouter()
+
+	resp, response := adminRequest(router, "POST", fmt.Sprintf("/sprints/%d/archive", active.ID), "owner", nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "sprint_active", response["code"])
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/sprints/%d/archive", planned.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, response = adminRequest(router, "GET", fmt.Sprintf("/projects/%d/sprints", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+
+	resp, response = adminRequest(router, "GET", fmt.Sprintf("/projects/%d/sprints?include_archived=true", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 2)
+
+	resp, response = adminRequest(router, "POST", fmt.Sprintf("/sprints/%d/start", planned.ID), "owner", nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "sprint_archived", response["code"])
+
+	resp, _ = adminRequest(router, "POST", "/tasks", "owner", map[string]interface{}{"title": "New", "status": "todo", "sprint_id": planned.ID})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/sprints/%d/unarchive", planned.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "POST", "/tasks", "owner", map[string]interface{}{"title": "New", "status": "todo", "sprint_id": planned.ID})
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
@@ -2,60 +2,141 @@ package controllers
 
 import (
 	"errors"
-	"kanban/config"
+	"kanban/apperror"
 	"kanban/middlewares"
 	"kanban/models"
+	"kanban/passwords"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-
 func Register(c *gin.Context) {
-	var input models.User
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.RegisterRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	if !checkPasswordPolicy(c, "password", input.Password) {
 		return
 	}
 
-	hashed, _ := bcrypt.GenerateFromPassword([]byte(input.Password), 10)
-	user := models.User{Username: input.Username, Password: string(hashed), Email: input.Email}
+	hashed, err := passwords.Hash(input.Password)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	user := models.User{Username: input.Username, Password: hashed, Email: strings.TrimSpace(input.Email)}
 	var existing models.User
-	if err := config.DB.Where("username = ?", user.Username).First(&existing).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "username already exists"})
+	if err := db(c).Where("username = ?", user.Username).First(&existing).Error; err == nil {
+		c.Error(apperror.Conflict(apperror.CodeUsernameTaken))
 		return
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		c.Error(apperror.Internal(err))
 		return
 	}
 
-	config.DB.Create(&user)
+	if user.Email != "" {
+		if taken, err := userFieldTaken(c, "email", user.Email, 0); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		} else if taken {
+			c.Error(apperror.Conflict(apperror.CodeEmailTaken))
+			return
+		}
+	}
+
+	if err := db(c).Create(&user).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "registered"})
 }
 
+// dummyPasswordHash dipakai saat username tidak ditemukan, agar waktu respon login
+// sama dengan username yang ada dan tidak bisa dipakai menebak akun
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hashed, _ := passwords.Hash("kanban-dummy-password")
+	return hashed
+})
 
 func Login(c *gin.Context) {
-	var input models.User
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.LoginRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	account, allowed := checkLoginThrottle(c, input.Username)
+	if !allowed {
 		return
 	}
 
 	var user models.User
-	if err := config.DB.Where("username = ?", input.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username"})
+	hash := dummyPasswordHash()
+	err := db(c).Where("username = ?", input.Username).First(&user).Error
+	if err == nil {
+		hash = user.Password
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid password"})
+	ok, rehash, err := passwords.Verify(input.Password, hash)
+	if err != nil && !errors.Is(err, passwords.ErrUnknownHash) {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
+	// Username tidak ditemukan dan password salah menghasilkan response yang sama
+	if !ok || user.ID == 0 {
+		if err := recordLoginFailure(c, input.Username); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		c.Error(apperror.Unauthorized(apperror.CodeInvalidCredentials))
+		return
+	}
+
+	// Status nonaktif baru diberitahukan setelah password terbukti benar
+	if user.DisabledAt != nil {
+		c.Error(apperror.Forbidden(apperror.CodeAccountDisabled))
+		return
+	}
+
+	// Hash dibuat ulang jika algoritma atau cost di konfigurasi sudah berubah
+	if rehash {
+		if hashed, err := passwords.Hash(input.Password); err != nil {
+			slog.WarnContext(c.Request.Context(), "Gagal membuat ulang hash password", "user_id", user.ID, "error", err)
+		} else if err := db(c).Model(&user).Update("password", hashed).Error; err != nil {
+			slog.WarnContext(c.Request.Context(), "Gagal menyimpan ulang hash password", "user_id", user.ID, "error", err)
+		}
+	}
+
+	// Dengan 2FA, password yang benar hanya menghasilkan challenge token; hitungan login gagal
+	// baru dihapus setelah kode 2FA diverifikasi agar lockout tidak bisa di-reset dengan password saja
+	if user.TOTPEnabled {
+		challenge, err := middlewares.GenerateChallengeToken(user.ID, user.Username, user.TokenVersion)
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"two_factor_required": true, "challenge_token": challenge})
+		return
+	}
+
+	if account.ID != 0 {
+		if err := clearAccountThrottle(db(c), user.Username); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
 	// generate JWT
-	token, _ := middlewares.GenerateJWT(user.Username)
+	token, _ := middlewares.GenerateJWT(user.ID, user.Username, user.TokenVersion)
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
@@ -8,9 +8,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"kanban/config"
+	"kanban/middlewares"
 	"kanban/models"
 
 	"github.com/gin-gonic/gin"
@@ -43,13 +47,14 @@ func setupTestDB() {
 		log.Fatal("Failed to connect test database:", err)
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Task{})
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Task{}, &models.LoginThrottle{})
 	config.DB = db
 }
 
 func teardownTestDB() {
 	if config.DB != nil {
 		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE login_throttles")
 		config.DB.Exec("TRUNCATE TABLE tasks")
 		config.DB.Exec("TRUNCATE TABLE projects")
 		config.DB.Exec("TRUNCATE TABLE users")
@@ -68,11 +73,12 @@ func TestRegister(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/register", Register)
 
-	user := models.User{
-		Username: "testuser",
-		Password: "testpass123",
+	user := map[string]string{
+		"username": "testuser",
+		"password": "testpass123",
 	}
 
 	jsonData, _ := json.Marshal(user)
@@ -108,11 +114,12 @@ func TestRegisterDuplicateUsername(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/register", Register)
 
-	user := models.User{
-		Username: "testuser",
-		Password: "anotherpass",
+	user := map[string]string{
+		"username": "testuser",
+		"password": "anotherpass",
 	}
 
 	jsonData, _ := json.Marshal(user)
@@ -122,8 +129,61 @@ func TestRegisterDuplicateUsername(t *testing.T) {
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
-	assert.NotEqual(t, http.StatusOK, resp.Code)
-	assert.Contains(t, []int{http.StatusBadRequest, http.StatusInternalServerError}, resp.Code)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "username_taken", response["code"])
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+
+	config.DB.Create(&models.User{Username: "existing", Password: "hashed", Email: "taken@example.com"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/register", Register)
+
+	jsonData, _ := json.Marshal(map[string]string{
+		"username": "newuser",
+		"password": "testpass123",
+		"email":    "taken@example.com",
+	})
+	req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "email_taken", response["code"])
+}
+
+func TestRegisterWithoutEmailTwice(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/register", Register)
+
+	for _, username := range []string{"first", "second"} {
+		jsonData, _ := json.Marshal(map[string]string{"username": username, "password": "testpass123"})
+		req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+	}
 }
 
 func TestRegisterInvalidJSON(t *testing.T) {
@@ -132,6 +192,7 @@ func TestRegisterInvalidJSON(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/register", Register)
 
 	req, _ := http.NewRequest("POST", "/register", bytes.NewBufferString("invalid json"))
@@ -157,11 +218,12 @@ func TestLogin(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/login", Login)
 
-	loginData := models.User{
-		Username: "logintest",
-		Password: password,
+	loginData := map[string]string{
+		"username": "logintest",
+		"password": password,
 	}
 
 	jsonData, _ := json.Marshal(loginData)
@@ -180,11 +242,12 @@ func TestLoginInvalidCredentials(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/login", Login)
 
-	loginData := models.User{
-		Username: "nonexistent",
-		Password: "wrongpass",
+	loginData := map[string]string{
+		"username": "nonexistent",
+		"password": "wrongpass",
 	}
 
 	jsonData, _ := json.Marshal(loginData)
@@ -195,6 +258,10 @@ func TestLoginInvalidCredentials(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "invalid_credentials", response["code"])
 }
 
 func TestLoginWrongPassword(t *testing.T) {
@@ -210,11 +277,12 @@ func TestLoginWrongPassword(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/login", Login)
 
-	loginData := models.User{
-		Username: "testuser",
-		Password: "wrongpassword",
+	loginData := map[string]string{
+		"username": "testuser",
+		"password": "wrongpassword",
 	}
 
 	jsonData, _ := json.Marshal(loginData)
@@ -225,6 +293,10 @@ func TestLoginWrongPassword(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "invalid_credentials", response["code"])
 }
 
 func TestLoginInvalidJSON(t *testing.T) {
@@ -233,6 +305,7 @@ func TestLoginInvalidJSON(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/login", Login)
 
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBufferString("invalid json"))
@@ -243,3 +316,184 @@ func TestLoginInvalidJSON(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
+
+func loginAttempt(router *gin.Engine, username, password string) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestLoginBackoff(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+	t.Setenv("LOGIN_BACKOFF_AFTER", "2")
+	t.Setenv("LOGIN_BACKOFF_BASE", "1m")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/login", Login)
+
+	assert.Equal(t, http.StatusUnauthorized, loginAttempt(router, "ghost", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, loginAttempt(router, "ghost", "wrong").Code)
+
+	// Username yang tidak terdaftar tetap kena backoff, sama seperti akun yang ada
+	resp := loginAttempt(router, "ghost", "wrong")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "too_many_login_attempts", response["code"])
+}
+
+func TestPurgeLoginThrottles(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+
+	now := time.Now()
+	blockedUntil := now.Add(time.Minute)
+	config.DB.Create(&models.LoginThrottle{Scope: models.ThrottleScopeIP, Key: "203.0.113.1", Failures: 1, LastFailureAt: now.Add(-time.Hour)})
+	config.DB.Create(&models.LoginThrottle{Scope: models.ThrottleScopeIP, Key: "203.0.113.2", Failures: 1, LastFailureAt: now})
+	// Lockout yang masih berlaku tetap disimpan walaupun gagal terakhirnya sudah lama
+	config.DB.Create(&models.LoginThrottle{Scope: models.ThrottleScopeAccount, Key: "locked", Failures: 10,
+		LastFailureAt: now.Add(-time.Hour), BlockedUntil: &blockedUntil, Locked: true})
+
+	deleted, err := PurgeLoginThrottles(config.DB, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	var keys []string
+	config.DB.Model(&models.LoginThrottle{}).Order("`key`").Pluck("key", &keys)
+	assert.Equal(t, []string{"203.0.113.2", "locked"}, keys)
+}
+
+func TestLoginLockoutAndAdminUnlock(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+	t.Setenv("LOGIN_BACKOFF_AFTER", "0")
+	t.Setenv("LOGIN_LOCKOUT_AFTER", "3")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpass"), bcrypt.DefaultCost)
+	user := models.User{Username: "target", Password: string(hashedPassword)}
+	config.DB.Create(&user)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/login", Login)
+	router.POST("/admin/users/:id/unlock", func(c *gin.Context) {
+		c.Set("is_admin", true)
+	}, middlewares.RequireAdmin(), UnlockUser)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, loginAttempt(router, "target", "wrong").Code)
+	}
+
+	// Password benar pun ditolak selama akun terkunci
+	resp := loginAttempt(router, "target", "correctpass")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "account_locked", response["code"])
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/users/%d/unlock", user.ID), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	assert.Equal(t, http.StatusOK, loginAttempt(router, "target", "correctpass").Code)
+}
+
+func registerAttempt(router *gin.Engine, username, password string) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestRegisterPasswordPolicy(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+	t.Setenv("PASSWORD_MIN_LENGTH", "10")
+	t.Setenv("PASSWORD_REQUIRE_DIGIT", "true")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/register", Register)
+
+	resp := registerAttempt(router, "weak", "short")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	errs := response["errors"].([]interface{})
+	codes := []interface{}{}
+	for _, e := range errs {
+		codes = append(codes, e.(map[string]interface{})["code"])
+	}
+	assert.ElementsMatch(t, []interface{}{"min", "passworddigit"}, codes)
+
+	assert.Equal(t, http.StatusOK, registerAttempt(router, "strong", "longenough1").Code)
+}
+
+func TestRegisterBreachedPassword(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+
+	// SHA-1("password123") = CBFDAC6008F9CAB4083784CBD1874F76618D2A97
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "CBFDA"), []byte("0000000000000000000000000000000000A:1\nC6008F9CAB4083784CBD1874F76618D2A97:250000\n"), 0o644)
+	t.Setenv("PASSWORD_BREACH_DIR", dir)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/register", Register)
+
+	resp := registerAttempt(router, "breached", "password123")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	errs := response["errors"].([]interface{})
+	assert.Equal(t, "breached", errs[0].(map[string]interface{})["code"])
+
+	assert.Equal(t, http.StatusOK, registerAttempt(router, "breached", "correct horse battery").Code)
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	setupTestDB()
+	defer teardownTestDB()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := models.User{Username: "legacy", Password: string(hashedPassword)}
+	config.DB.Create(&user)
+
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	t.Setenv("ARGON2_MEMORY_KIB", "1024")
+	t.Setenv("ARGON2_ITERATIONS", "1")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/login", Login)
+
+	assert.Equal(t, http.StatusOK, loginAttempt(router, "legacy", "password123").Code)
+
+	var updated models.User
+	config.DB.First(&updated, user.ID)
+	assert.True(t, strings.HasPrefix(updated.Password, "$argon2id$"))
+
+	// Hash baru tetap bisa dipakai login
+	assert.Equal(t, http.StatusOK, loginAttempt(router, "legacy", "password123").Code)
+}
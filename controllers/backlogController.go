@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetBacklog mendapatkan task project yang belum masuk sprint, urut sesuai ranking backlog
+func GetBacklog(c *gin.Context) {
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+
+	var tasks []models.Task
+	if err := backlogOf(db(c), project.ID).Preload("User").Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tasks})
+}
+
+// ReorderBacklog menyusun ulang ranking backlog. Task pada task_ids ditaruh paling atas
+// sesuai urutan yang dikirim, sisa backlog tetap di bawahnya dengan urutan lama.
+func ReorderBacklog(c *gin.Context) {
+	var input models.BacklogTasksRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+	if err := writableProject(&project); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var tasks []models.Task
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := backlogOf(tx.Clauses(clause.Locking{Strength: "UPDATE"}), project.ID).Find(&tasks).Error; err != nil {
+			return err
+		}
+
+		byID := make(map[uint]models.Task, len(tasks))
+		for _, task := range tasks {
+			byID[task.ID] = task
+		}
+
+		ordered := make([]models.Task, 0, len(tasks))
+		for _, id := range input.TaskIDs {
+			task, ok := byID[id]
+			if !ok {
+				return apperror.Validation(apperror.FieldError{Field: "task_ids", Code: "backlogtask"})
+			}
+			ordered = append(ordered, task)
+			delete(byID, id)
+		}
+		for _, task := range tasks {
+			if _, rest := byID[task.ID]; rest {
+				ordered = append(ordered, task)
+			}
+		}
+
+		for i := range ordered {
+			ordered[i].BacklogRank = i + 1
+			if err := tx.Model(&ordered[i]).Update("backlog_rank", ordered[i].BacklogRank).Error; err != nil {
+				return err
+			}
+		}
+		tasks = ordered
+		return nil
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tasks})
+}
+
+// PullIntoSprint memindahkan task dari backlog project ke sprint yang masih planned.
+// Response menyertakan warning planning, misalnya jika sprint menjadi over-commit.
+func PullIntoSprint(c *gin.Context) {
+	var input models.BacklogTasksRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	var sprint models.Sprint
+	var plan models.SprintPlan
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockSprint(tx.Scopes(tenantRecords(c, "sprints")), c.Param("id"), &sprint); err != nil {
+			return err
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, &sprint.ID); err != nil {
+			return err
+		}
+		if sprint.Status != models.SprintStatusPlanned {
+			return sprintNotPlanned(&sprint)
+		}
+
+		var tasks []models.Task
+		if err := backlogOf(tx.Clauses(clause.Locking{Strength: "UPDATE"}), sprint.ProjectID).
+			Where("id IN ?", input.TaskIDs).Find(&tasks).Error; err != nil {
+			return err
+		}
+		if len(tasks) != len(input.TaskIDs) {
+			return apperror.Validation(apperror.FieldError{Field: "task_ids", Code: "backlogtask"})
+		}
+
+		if err := tx.Model(&models.Task{}).Where("id IN ?", input.TaskIDs).Updates(map[string]interface{}{
+			"sprint_id":    sprint.ID,
+			"backlog_rank": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Rencana sprint dihitung ulang agar client langsung tahu jika sprint over-commit
+		var err error
+		plan, err = sprintPlan(tx, &sprint)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sprint, "warnings": plan.Warnings})
+}
+
+// PushToBacklog mengembalikan task dari sprint yang masih planned ke urutan terbawah backlog
+func PushToBacklog(c *gin.Context) {
+	var task models.Task
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(tenantRecords(c, "tasks")).Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeTaskNotFound)
+		}
+		if task.SprintID == nil {
+			return apperror.Conflict(apperror.CodeTaskInBacklog)
+		}
+		if err := ensureWritable(tx, task.ProjectID, task.SprintID); err != nil {
+			return err
+		}
+
+		var sprint models.Sprint
+		if err := lockSprint(tx, *task.SprintID, &sprint); err != nil {
+			return err
+		}
+		if sprint.Status != models.SprintStatusPlanned {
+			return sprintNotPlanned(&sprint)
+		}
+
+		rank, err := nextBacklogRank(tx, task.ProjectID)
+		if err != nil {
+			return err
+		}
+		task.SprintID = nil
+		task.BacklogRank = rank
+		return tx.Select("SprintID", "BacklogRank").Save(&task).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": task})
+}
+
+// backlogOf membatasi query ke task backlog project, urut sesuai ranking
+func backlogOf(tx *gorm.DB, projectID uint) *gorm.DB {
+	return tx.Where("project_id = ? AND sprint_id IS NULL", projectID).Order("backlog_rank, id")
+}
+
+// nextBacklogRank mengembalikan ranking untuk task yang ditaruh paling bawah di backlog
+func nextBacklogRank(tx *gorm.DB, projectID uint) (int, error) {
+	var rank int
+	err := tx.Model(&models.Task{}).
+		Where("project_id = ? AND sprint_id IS NULL", projectID).
+		Select("COALESCE(MAX(backlog_rank), 0) + 1").
+		Scan(&rank).Error
+	return rank, err
+}
+
+func sprintNotPlanned(sprint *models.Sprint) *apperror.Error {
+	return apperror.Conflict(apperror.CodeSprintNotPlanned).
+		WithDetails(map[string]interface{}{"sprint_id": sprint.ID, "status": sprint.Status})
+}
@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupBacklogTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{}, &models.TaskStatusTransition{},
+		&models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownBacklogTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE task_status_transitions")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func createBacklogTestSprint(projectID uint, status string) models.Sprint {
+	sprint := models.Sprint{
+		Name:           "Sprint",
+		ProjectID:      projectID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 14),
+		Status:         status,
+	}
+	config.DB.Create(&sprint)
+	return sprint
+}
+
+func TestCreateTaskInBacklog(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	config.DB.Create(&models.Task{Title: "Existing", Status: "todo", ProjectID: project.ID, BacklogRank: 1})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/tasks", CreateTask)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"title":      "Backlog Task",
+		"status":     "todo",
+		"project_id": project.ID,
+		"estimation": 3,
+	})
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var task models.Task
+	config.DB.Where("title = ?", "Backlog Task").First(&task)
+	assert.Nil(t, task.SprintID)
+	assert.Equal(t, project.ID, task.ProjectID)
+	assert.Equal(t, 2, task.BacklogRank)
+}
+
+func TestCreateTaskWithoutProjectOrSprint(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/tasks", CreateTask)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"title": "Orphan", "status": "todo"})
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.Task{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestGetBacklogOrderedByRank(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := createBacklogTestSprint(project.ID, "planned")
+
+	config.DB.Create(&models.Task{Title: "Second", Status: "todo", ProjectID: project.ID, BacklogRank: 2})
+	config.DB.Create(&models.Task{Title: "First", Status: "todo", ProjectID: project.ID, BacklogRank: 1})
+	config.DB.Create(&models.Task{Title: "In Sprint", Status: "todo", SprintID: &sprint.ID})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/projects/:id/backlog", GetBacklog)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/backlog", project.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data []models.Task `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	if assert.Len(t, response.Data, 2) {
+		assert.Equal(t, "First", response.Data[0].Title)
+		assert.Equal(t, "Second", response.Data[1].Title)
+	}
+}
+
+func TestReorderBacklog(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	first := models.Task{Title: "First", Status: "todo", ProjectID: project.ID, BacklogRank: 1}
+	second := models.Task{Title: "Second", Status: "todo", ProjectID: project.ID, BacklogRank: 2}
+	third := models.Task{Title: "Third", Status: "todo", ProjectID: project.ID, BacklogRank: 3}
+	config.DB.Create(&first)
+	config.DB.Create(&second)
+	config.DB.Create(&third)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.PUT("/projects/:id/backlog", ReorderBacklog)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"task_ids": []uint{third.ID}})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/projects/%d/backlog", project.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tasks []models.Task
+	config.DB.Order("backlog_rank").Find(&tasks)
+	if assert.Len(t, tasks, 3) {
+		assert.Equal(t, third.ID, tasks[0].ID)
+		assert.Equal(t, first.ID, tasks[1].ID)
+		assert.Equal(t, second.ID, tasks[2].ID)
+	}
+}
+
+func TestPullIntoSprint(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := createBacklogTestSprint(project.ID, "planned")
+
+	task := models.Task{Title: "Backlog Task", Status: "todo", ProjectID: project.ID, BacklogRank: 1}
+	config.DB.Create(&task)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/tasks", PullIntoSprint)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"task_ids": []uint{task.ID}})
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/tasks", sprint.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var pulled models.Task
+	config.DB.First(&pulled, task.ID)
+	if assert.NotNil(t, pulled.SprintID) {
+		assert.Equal(t, sprint.ID, *pulled.SprintID)
+	}
+	assert.Equal(t, 0, pulled.BacklogRank)
+}
+
+func TestPullIntoActiveSprintRejected(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := createBacklogTestSprint(project.ID, "active")
+
+	task := models.Task{Title: "Backlog Task", Status: "todo", ProjectID: project.ID, BacklogRank: 1}
+	config.DB.Create(&task)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/tasks", PullIntoSprint)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"task_ids": []uint{task.ID}})
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/tasks", sprint.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "sprint_not_planned", response["code"])
+
+	var unchanged models.Task
+	config.DB.First(&unchanged, task.ID)
+	assert.Nil(t, unchanged.SprintID)
+}
+
+func TestPushToBacklog(t *testing.T) {
+	setupBacklogTestDB()
+	defer teardownBacklogTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := createBacklogTestSprint(project.ID, "planned")
+
+	config.DB.Create(&models.Task{Title: "Backlog Task", Status: "todo", ProjectID: project.ID, BacklogRank: 1})
+	task := models.Task{Title: "Sprint Task", Status: "todo", SprintID: &sprint.ID}
+	config.DB.Create(&task)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/tasks/:id/backlog", PushToBacklog)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/tasks/%d/backlog", task.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var pushed models.Task
+	config.DB.First(&pushed, task.ID)
+	assert.Nil(t, pushed.SprintID)
+	assert.Equal(t, project.ID, pushed.ProjectID)
+	assert.Equal(t, 2, pushed.BacklogRank)
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"kanban/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// db mengembalikan koneksi database yang membawa context request,
+// sehingga log query, metrics dan tracing ikut mencatat request ID dan span
+func db(c *gin.Context) *gorm.DB {
+	return config.DB.WithContext(c.Request.Context())
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/models"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SendEmailVerification mengirim link verifikasi ke email user yang sedang login.
+// Link sebelumnya yang belum dipakai tidak berlaku lagi.
+func SendEmailVerification(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if user.Email == "" {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "email", Code: "required"}))
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		c.Error(apperror.Conflict(apperror.CodeEmailAlreadyVerified))
+		return
+	}
+
+	ttl := config.GetEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	verification, token, err := models.NewEmailVerificationToken(user.ID, user.Email, ttl)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		if err := invalidateVerificationTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Create(&verification).Error
+	})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	link := config.GetEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/email/verify") + "?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Kanban email address",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to verify your email address. It expires in %s and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Username, ttl, link),
+	}
+	if err := mailer.Send(c.Request.Context(), msg); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Gagal mengirim email verifikasi", "user_id", user.ID, "error", err)
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "A verification link has been sent to your email"})
+}
+
+// VerifyEmail memakai token verifikasi untuk menandai email user sebagai terverifikasi.
+// Token hanya berlaku jika email user masih sama dengan email saat token dibuat.
+func VerifyEmail(c *gin.Context) {
+	var input models.VerifyEmailRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	var user models.User
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		var verification models.EmailVerificationToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", models.HashToken(input.Token)).First(&verification).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.BadRequest(apperror.CodeInvalidVerifyToken)
+			}
+			return err
+		}
+		if !verification.Usable(time.Now()) {
+			return apperror.BadRequest(apperror.CodeInvalidVerifyToken)
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, verification.UserID).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeInvalidVerifyToken)
+		}
+		if user.Email != verification.Email {
+			return apperror.BadRequest(apperror.CodeInvalidVerifyToken)
+		}
+
+		if err := invalidateVerificationTokens(tx, user.ID); err != nil {
+			return err
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// invalidateVerificationTokens menandai semua token verifikasi user yang belum dipakai sebagai sudah dipakai
+func invalidateVerificationTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
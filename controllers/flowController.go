@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFlowRangeDays membatasi rentang cumulative flow project agar query tetap ringan
+const maxFlowRangeDays = 366
+
+// GetSprintFlow mendapatkan cumulative flow harian dan lead/cycle time untuk task dalam sprint
+func GetSprintFlow(c *gin.Context) {
+	var sprint models.Sprint
+	if err := db(c).Scopes(tenantRecords(c, "sprints")).First(&sprint, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeSprintNotFound))
+		return
+	}
+
+	var tasks []models.Task
+	if err := db(c).Where("sprint_id = ?", sprint.ID).Preload("Transitions").Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	from, to := sprint.ReportingPeriod()
+	c.JSON(http.StatusOK, gin.H{"data": models.BuildFlowReport(tasks, from, to)})
+}
+
+// GetProjectFlow mendapatkan cumulative flow harian dan lead/cycle time untuk semua task project
+func GetProjectFlow(c *gin.Context) {
+	var query models.FlowQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -29)
+	}
+	if query.To.Sub(query.From) > maxFlowRangeDays*24*time.Hour {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "from", Code: "maxrange", Param: strconv.Itoa(maxFlowRangeDays)}))
+		return
+	}
+
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+
+	var tasks []models.Task
+	if err := db(c).Where("project_id = ? AND created_at < ?", project.ID, query.To.AddDate(0, 0, 1)).
+		Preload("Transitions").Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.BuildFlowReport(tasks, query.From, query.To)})
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupFlowTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.Project{}, &models.Sprint{}, &models.Task{}, &models.TaskStatusTransition{}, &models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownFlowTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE task_status_transitions")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+// createFlowTask membuat task dengan riwayat status; statuses[i] terjadi pada start + i hari
+func createFlowTask(sprint *models.Sprint, start time.Time, statuses ...string) models.Task {
+	task := models.Task{Title: "Task", Status: statuses[len(statuses)-1], SprintID: &sprint.ID}
+	task.CreatedAt = start
+	config.DB.Create(&task)
+
+	from := ""
+	for i, status := range statuses {
+		config.DB.Create(&models.TaskStatusTransition{
+			TaskID:     task.ID,
+			ProjectID:  sprint.ProjectID,
+			SprintID:   &sprint.ID,
+			FromStatus: from,
+			ToStatus:   status,
+			ChangedAt:  start.AddDate(0, 0, i),
+		})
+		from = status
+	}
+	return task
+}
+
+func TestGetSprintFlow(t *testing.T) {
+	setupFlowTestDB()
+	defer teardownFlowTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	completedAt := start.AddDate(0, 0, 4)
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      start,
+		EndDate:        completedAt,
+		Status:         "completed",
+		StartedAt:      &start,
+		CompletedAt:    &completedAt,
+	}
+	config.DB.Create(&sprint)
+
+	createFlowTask(&sprint, start, "todo", "in_progress", "done")
+	createFlowTask(&sprint, start, "todo", "in_progress", "in_progress", "done")
+	createFlowTask(&sprint, start, "todo")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/sprints/:id/flow", GetSprintFlow)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/sprints/%d/flow", sprint.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data models.FlowReport `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	report := response.Data
+
+	if assert.Len(t, report.CumulativeFlow, 5) {
+		assert.Equal(t, 3, report.CumulativeFlow[0].Counts["todo"])
+		assert.Equal(t, 2, report.CumulativeFlow[1].Counts["in_progress"])
+		assert.Equal(t, 1, report.CumulativeFlow[2].Counts["done"])
+		assert.Equal(t, 2, report.CumulativeFlow[4].Counts["done"])
+	}
+
+	assert.Equal(t, 2, report.LeadTime.Count)
+	assert.Equal(t, 48.0, report.LeadTime.MedianHours)
+	assert.Equal(t, 72.0, report.LeadTime.P85Hours)
+	assert.Equal(t, 2, report.CycleTime.Count)
+	assert.Equal(t, 24.0, report.CycleTime.MedianHours)
+	assert.Equal(t, 1, report.CycleTime.Histogram[1].Count)
+	assert.Equal(t, 1, report.CycleTime.Histogram[2].Count)
+}
+
+func TestGetProjectFlowRejectsLongRange(t *testing.T) {
+	setupFlowTestDB()
+	defer teardownFlowTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/projects/:id/flow", GetProjectFlow)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/flow?from=2020-01-01&to=2024-01-01", project.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "validation_failed", response["code"])
+}
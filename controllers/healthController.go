@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"kanban/config"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz adalah liveness probe: proses hidup dan bisa melayani request
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz adalah readiness probe: database bisa di-ping dan tidak ada migrasi yang tertunda.
+// Status migrasi diperiksa sekali saat startup; detail error hanya dicatat di log.
+func Readyz(c *gin.Context) {
+	checks := gin.H{}
+	ready := true
+
+	if config.DB == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not_ready",
+			"checks": gin.H{"database": "not connected"},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	sqlDB, err := config.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Readiness probe gagal ping database", "error", err)
+		ready = false
+		checks["database"] = "unavailable"
+	} else {
+		checks["database"] = "ok"
+	}
+
+	if ready {
+		pending, checked := config.MigrationStatus()
+		if !checked {
+			err = config.CheckMigrations(config.DB.WithContext(ctx))
+			pending, _ = config.MigrationStatus()
+		}
+		switch {
+		case err != nil:
+			ready = false
+			checks["migrations"] = "unavailable"
+		case len(pending) > 0:
+			ready = false
+			checks["migrations"] = gin.H{"pending": pending}
+		default:
+			checks["migrations"] = "ok"
+		}
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"kanban/config"
+	"kanban/metrics"
+	"kanban/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupHealthTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(config.Models...)
+
+	config.DB = db
+}
+
+func teardownHealthTestDB() {
+	if config.DB != nil {
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", Healthz)
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "ok", response["status"])
+}
+
+func TestReadyz(t *testing.T) {
+	setupHealthTestDB()
+	defer teardownHealthTestDB()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", Readyz)
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "ready", response["status"])
+}
+
+func TestReadyzDatabaseDown(t *testing.T) {
+	setupHealthTestDB()
+	teardownHealthTestDB()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", Readyz)
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "not_ready", response["status"])
+}
+
+// requestDurationCount mengembalikan jumlah observasi histogram latensi untuk satu route
+func requestDurationCount(t *testing.T, method, route string) uint64 {
+	var m dto.Metric
+	observer := metrics.HTTPRequestDuration.WithLabelValues(method, route)
+	if err := observer.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMetricsUseRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.Metrics())
+	router.GET("/metrics-test/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	requests := metrics.HTTPRequestsTotal.WithLabelValues("GET", "/metrics-test/:id", "204")
+	unmatched := metrics.HTTPRequestsTotal.WithLabelValues("GET", "unmatched", "404")
+	beforeRequests := testutil.ToFloat64(requests)
+	beforeUnmatched := testutil.ToFloat64(unmatched)
+	beforeDuration := requestDurationCount(t, "GET", "/metrics-test/:id")
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test-missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(requests)-beforeRequests)
+	assert.Equal(t, 1.0, testutil.ToFloat64(unmatched)-beforeUnmatched)
+	assert.Equal(t, uint64(2), requestDurationCount(t, "GET", "/metrics-test/:id")-beforeDuration)
+
+	// Path mentah tidak boleh menjadi label
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "route" {
+					assert.NotContains(t, []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test-missing"}, label.GetValue())
+				}
+			}
+		}
+	}
+}
+
+func TestMetricsRequireToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/metrics", middlewares.RequireMetricsToken("scrape-secret"), gin.WrapH(metrics.Handler()))
+	router.GET("/metrics-disabled", middlewares.RequireMetricsToken(""), gin.WrapH(metrics.Handler()))
+
+	for _, tc := range []struct {
+		path, authorization string
+		status              int
+	}{
+		{"/metrics", "", http.StatusUnauthorized},
+		{"/metrics", "Bearer wrong", http.StatusUnauthorized},
+		{"/metrics", "Bearer scrape-secret", http.StatusOK},
+		{"/metrics-disabled", "Bearer anything", http.StatusUnauthorized},
+	} {
+		req, _ := http.NewRequest("GET", tc.path, nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, tc.status, resp.Code, "%s %q", tc.path, tc.authorization)
+	}
+}
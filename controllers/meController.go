@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"errors"
+	"kanban/apperror"
+	"kanban/middlewares"
+	"kanban/models"
+	"kanban/passwords"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentUser mengambil user yang sedang login berdasarkan user_id dari AuthMiddleware,
+// atau username jika hanya itu yang tersedia. Hasilnya disimpan di context sehingga lookup
+// hanya terjadi sekali per request.
+func currentUser(c *gin.Context) (*models.User, error) {
+	if user, ok := c.Get("current_user"); ok {
+		return user.(*models.User), nil
+	}
+
+	query := db(c)
+	if userID := c.GetUint("user_id"); userID != 0 {
+		query = query.Where("id = ?", userID)
+	} else if name := c.GetString("username"); name != "" {
+		query = query.Where("username = ?", name)
+	} else {
+		return nil, apperror.Unauthorized(apperror.CodeInvalidToken)
+	}
+
+	var user models.User
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Token valid tetapi user-nya sudah tidak ada
+			return nil, apperror.Unauthorized(apperror.CodeInvalidToken)
+		}
+		return nil, apperror.Internal(err)
+	}
+
+	c.Set("current_user", &user)
+	return &user, nil
+}
+
+// GetMe mendapatkan profil user yang sedang login
+func GetMe(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// UpdateMe mengubah profil user yang sedang login. Mengganti username mencabut semua sesi
+// lama dan response menyertakan token baru untuk sesi ini.
+func UpdateMe(c *gin.Context) {
+	var input models.UpdateProfileRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Username != nil && *input.Username != user.Username {
+		if taken, err := userFieldTaken(c, "username", *input.Username, user.ID); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		} else if taken {
+			c.Error(apperror.Conflict(apperror.CodeUsernameTaken))
+			return
+		}
+		updates["username"] = *input.Username
+		// Token lama memuat username lama; versinya dinaikkan di update yang sama
+		updates["token_version"] = gorm.Expr("token_version + 1")
+	}
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		switch {
+		case email == user.Email:
+		case email == "":
+			updates["email"] = nil
+			updates["email_verified_at"] = nil
+		default:
+			if taken, err := userFieldTaken(c, "email", email, user.ID); err != nil {
+				c.Error(apperror.Internal(err))
+				return
+			} else if taken {
+				c.Error(apperror.Conflict(apperror.CodeEmailTaken))
+				return
+			}
+			updates["email"] = email
+			// Email baru harus diverifikasi ulang
+			updates["email_verified_at"] = nil
+		}
+	}
+	if input.DisplayName != nil {
+		updates["display_name"] = strings.TrimSpace(*input.DisplayName)
+	}
+	if input.AvatarURL != nil {
+		updates["avatar_url"] = *input.AvatarURL
+	}
+
+	usernameChanged := updates["username"] != nil
+	if len(updates) > 0 {
+		if err := db(c).Model(user).Updates(updates).Error; err != nil {
+			c.Error(err)
+			return
+		}
+		if err := db(c).First(user, user.ID).Error; err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
+	response := gin.H{"data": user}
+	if usernameChanged {
+		token, err := middlewares.GenerateJWT(user.ID, user.Username, user.TokenVersion)
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		response["token"] = token
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ChangePassword mengganti password user yang sedang login setelah memverifikasi password saat ini.
+// Sesi lain dicabut dan response menyertakan token baru untuk sesi ini. Token akses personal
+// ikut dicabut jika revoke_tokens diisi.
+func ChangePassword(c *gin.Context) {
+	var input models.ChangePasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if ok, _, err := passwords.Verify(input.CurrentPassword, user.Password); err != nil && !errors.Is(err, passwords.ErrUnknownHash) {
+		c.Error(apperror.Internal(err))
+		return
+	} else if !ok {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "current_password", Code: "currentpassword"}))
+		return
+	}
+	if !checkPasswordPolicy(c, "new_password", input.NewPassword) {
+		return
+	}
+
+	hashed, err := passwords.Hash(input.NewPassword)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	// Menaikkan TokenVersion mencabut sesi lain; sesi ini mendapat token baru
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"password":      hashed,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if !input.RevokeTokens {
+			return nil
+		}
+		return revokePersonalAccessTokens(tx, user.ID)
+	})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	token, err := middlewares.GenerateJWT(user.ID, user.Username, user.TokenVersion+1)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully", "token": token})
+}
+
+// userFieldTaken mengecek apakah nilai kolom unik (username/email) sudah dipakai user lain
+func userFieldTaken(c *gin.Context, column, value string, exceptID uint) (bool, error) {
+	var count int64
+	err := db(c).Model(&models.User{}).Where(column+" = ? AND id <> ?", value, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetMyTasks mendapatkan semua task yang di-assign ke user yang sedang login,
+// dikelompokkan per sprint (atau backlog) lalu per status
+func GetMyTasks(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var tasks []models.Task
+	if err := db(c).Scopes(tenantRecords(c, "tasks")).Where("assign_to = ?", user.ID).Preload("Sprint").Order("id").Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.GroupTasksBySprint(tasks), "total": len(tasks)})
+}
@@ -0,0 +1,376 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupMeTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{}, &models.PersonalAccessToken{},
+		&models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownMeTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE personal_access_tokens")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+// asUser menggantikan AuthMiddleware di test dengan username yang sudah login
+func asUser(username string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("username", username)
+		c.Next()
+	}
+}
+
+func TestGetMyTasks(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	me := models.User{Username: "me", Password: "hashed", Email: "me@example.com"}
+	other := models.User{Username: "other", Password: "hashed", Email: "other@example.com"}
+	config.DB.Create(&me)
+	config.DB.Create(&other)
+
+	org := seedTenant(me, other)
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	config.DB.Create(&sprint)
+
+	config.DB.Create(&models.Task{Title: "Mine todo", Status: "todo", SprintID: &sprint.ID, AssignTo: &me.ID, Estimation: 3})
+	config.DB.Create(&models.Task{Title: "Mine done", Status: "done", SprintID: &sprint.ID, AssignTo: &me.ID, Estimation: 2})
+	config.DB.Create(&models.Task{Title: "Mine backlog", Status: "todo", ProjectID: project.ID, AssignTo: &me.ID, Estimation: 1})
+	config.DB.Create(&models.Task{Title: "Not mine", Status: "todo", SprintID: &sprint.ID, AssignTo: &other.ID})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/me/tasks", asUser("me"), GetMyTasks)
+
+	req, _ := http.NewRequest("GET", "/me/tasks", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data  []models.SprintTaskGroup `json:"data"`
+		Total int                      `json:"total"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Equal(t, 3, response.Total)
+	if assert.Len(t, response.Data, 2) {
+		active := response.Data[0]
+		if assert.NotNil(t, active.Sprint) {
+			assert.Equal(t, sprint.ID, active.Sprint.ID)
+		}
+		assert.Len(t, active.TasksByStatus["todo"], 1)
+		assert.Len(t, active.TasksByStatus["done"], 1)
+		assert.Equal(t, 3.0, active.RemainingEstimation)
+
+		backlog := response.Data[1]
+		assert.Nil(t, backlog.Sprint)
+		assert.Len(t, backlog.TasksByStatus["todo"], 1)
+	}
+}
+
+func TestGetMyTasksUnknownUser(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/me/tasks", asUser("ghost"), GetMyTasks)
+
+	req, _ := http.NewRequest("GET", "/me/tasks", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestGetMeHidesPassword(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	config.DB.Create(&models.User{Username: "me", Password: "hashed", Email: "me@example.com", DisplayName: "Me"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/me", asUser("me"), GetMe)
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "me", response["data"]["username"])
+	assert.Equal(t, "Me", response["data"]["display_name"])
+	assert.NotContains(t, response["data"], "password")
+}
+
+func TestUpdateMe(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	me := models.User{Username: "me", Password: "hashed"}
+	config.DB.Create(&me)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.PATCH("/me", asUser("me"), UpdateMe)
+
+	jsonData, _ := json.Marshal(map[string]string{
+		"username":     "renamed",
+		"email":        "renamed@example.com",
+		"display_name": "Renamed User",
+		"avatar_url":   "https://example.com/avatar.png",
+	})
+	req, _ := http.NewRequest("PATCH", "/me", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NotEmpty(t, response["token"])
+
+	var updated models.User
+	config.DB.First(&updated, me.ID)
+	assert.Equal(t, "renamed", updated.Username)
+	assert.Equal(t, "renamed@example.com", updated.Email)
+	assert.Equal(t, "Renamed User", updated.DisplayName)
+	assert.Equal(t, "https://example.com/avatar.png", updated.AvatarURL)
+}
+
+func TestUpdateMeRenameRevokesOldToken(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	me := models.User{Username: "me", Password: "hashed"}
+	config.DB.Create(&me)
+	oldToken, _ := middlewares.GenerateJWT(me.ID, me.Username, me.TokenVersion)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/me", middlewares.AuthMiddleware(), GetMe)
+	router.PATCH("/me", middlewares.AuthMiddleware(), UpdateMe)
+
+	jsonData, _ := json.Marshal(map[string]string{"username": "renamed"})
+	req, _ := http.NewRequest("PATCH", "/me", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", oldToken)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	newToken, _ := response["token"].(string)
+
+	// Username lama dipakai user baru; token lama tidak boleh masuk sebagai user tersebut
+	config.DB.Create(&models.User{Username: "me", Password: "hashed"})
+
+	req, _ = http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", oldToken)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", newToken)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, float64(me.ID), response["data"].(map[string]interface{})["id"])
+}
+
+func TestUpdateMeEmailTaken(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	config.DB.Create(&models.User{Username: "me", Password: "hashed"})
+	config.DB.Create(&models.User{Username: "other", Password: "hashed", Email: "taken@example.com"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.PATCH("/me", asUser("me"), UpdateMe)
+
+	jsonData, _ := json.Marshal(map[string]string{"email": "taken@example.com"})
+	req, _ := http.NewRequest("PATCH", "/me", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "email_taken", response["code"])
+}
+
+func TestUpdateMeInvalidEmail(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	config.DB.Create(&models.User{Username: "me", Password: "hashed"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.PATCH("/me", asUser("me"), UpdateMe)
+
+	jsonData, _ := json.Marshal(map[string]string{"email": "not-an-email"})
+	req, _ := http.NewRequest("PATCH", "/me", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestChangePassword(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("oldpass123"), bcrypt.DefaultCost)
+	me := models.User{Username: "me", Password: string(hashed)}
+	config.DB.Create(&me)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.PUT("/me/password", asUser("me"), ChangePassword)
+
+	jsonData, _ := json.Marshal(map[string]string{"current_password": "wrongpass", "new_password": "newpass456"})
+	req, _ := http.NewRequest("PUT", "/me/password", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	jsonData, _ = json.Marshal(map[string]string{"current_password": "oldpass123", "new_password": "newpass456"})
+	req, _ = http.NewRequest("PUT", "/me/password", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updated models.User
+	config.DB.First(&updated, me.ID)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte("newpass456")))
+}
+
+func TestChangePasswordRevokeTokens(t *testing.T) {
+	setupMeTestDB()
+	defer teardownMeTestDB()
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("oldpass123"), bcrypt.DefaultCost)
+	me := models.User{Username: "me", Password: string(hashed)}
+	config.DB.Create(&me)
+	pat, _, _ := models.NewPersonalAccessToken(me.ID, "ci", []string{models.ScopeRead}, nil)
+	config.DB.Create(&pat)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.PUT("/me/password", asUser("me"), ChangePassword)
+
+	changePassword := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest("PUT", "/me/password", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Tanpa revoke_tokens token akses personal tetap berlaku
+	resp := changePassword(map[string]interface{}{"current_password": "oldpass123", "new_password": "newpass456"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	config.DB.First(&pat, pat.ID)
+	assert.Nil(t, pat.RevokedAt)
+
+	resp = changePassword(map[string]interface{}{"current_password": "newpass456", "new_password": "another789", "revoke_tokens": true})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	config.DB.First(&pat, pat.ID)
+	assert.NotNil(t, pat.RevokedAt)
+}
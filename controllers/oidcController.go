@@ -0,0 +1,403 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+	"kanban/oidc"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetOIDCProviders mendapatkan nama provider SSO yang bisa dipakai di halaman login
+func GetOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": oidc.Names()})
+}
+
+// OIDCLogin mengarahkan browser ke identity provider (authorization code + PKCE)
+func OIDCLogin(c *gin.Context) {
+	provider, err := oidcProvider(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	authURL, err := startOIDCFlow(c, provider, nil)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback menerima redirect dari identity provider, menukar code dengan ID token,
+// lalu login sebagai user yang tertaut (atau menautkan identity untuk alur link).
+// User baru dibuat otomatis jika provider mengizinkan just-in-time provisioning.
+func OIDCCallback(c *gin.Context) {
+	provider, err := oidcProvider(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		c.Error(apperror.Unauthorized(apperror.CodeOIDCLoginFailed).
+			WithDetails(map[string]interface{}{"error": idpErr, "error_description": c.Query("error_description")}))
+		return
+	}
+
+	state, err := consumeOIDCState(c, provider.Name, c.Query("state"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), c.Query("code"), state.CodeVerifier, state.Nonce)
+	if err != nil {
+		c.Error(&apperror.Error{Status: http.StatusUnauthorized, Code: apperror.CodeOIDCLoginFailed, Cause: err})
+		return
+	}
+
+	if state.LinkUserID != nil {
+		identity, err := linkIdentity(c, provider.Name, claims, *state.LinkUserID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Identity linked successfully", "data": identity})
+		return
+	}
+
+	var user models.User
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = resolveOIDCUser(tx, provider, claims)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if user.DisabledAt != nil {
+		c.Error(apperror.Forbidden(apperror.CodeAccountDisabled))
+		return
+	}
+
+	// Sama seperti POST /login: user dengan 2FA harus melanjutkan ke POST /login/2fa
+	if user.TOTPEnabled {
+		challenge, err := middlewares.GenerateChallengeToken(user.ID, user.Username, user.TokenVersion)
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"two_factor_required": true, "challenge_token": challenge})
+		return
+	}
+
+	token, err := middlewares.GenerateJWT(user.ID, user.Username, user.TokenVersion)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// GetMyIdentities mendapatkan identity SSO yang tertaut ke user yang sedang login
+func GetMyIdentities(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var identities []models.UserIdentity
+	if err := db(c).Where("user_id = ?", user.ID).Order("id").Find(&identities).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": identities})
+}
+
+// LinkMyIdentity memulai alur OIDC untuk menautkan identity ke user yang sedang login.
+// Client membuka authorization_url di browser; callback menautkan identity-nya.
+func LinkMyIdentity(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	provider, err := oidcProvider(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	authURL, err := startOIDCFlow(c, provider, &user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+// UnlinkMyIdentity melepas identity SSO. Identity terakhir tidak bisa dilepas jika
+// user tidak punya password, karena user tidak akan bisa login lagi.
+func UnlinkMyIdentity(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockUser(tx, user.ID); err != nil {
+			return err
+		}
+
+		var identity models.UserIdentity
+		if err := tx.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&identity).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeNotFound)
+		}
+
+		var count int64
+		if err := tx.Model(&models.UserIdentity{}).Where("user_id = ?", user.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 1 && user.Password == "" {
+			return apperror.Conflict(apperror.CodeLastLoginMethod)
+		}
+		return tx.Delete(&identity).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identity unlinked successfully"})
+}
+
+func oidcProvider(c *gin.Context) (*oidc.Provider, error) {
+	provider, err := oidc.Get(c.Param("provider"))
+	if err != nil {
+		return nil, apperror.NotFound(apperror.CodeOIDCProviderNotFound)
+	}
+	return provider, nil
+}
+
+// Cookie pengikat state OIDC ke browser; hanya dikirim ke route /auth/oidc
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/auth/oidc"
+)
+
+// startOIDCFlow menyimpan state, nonce dan code verifier lalu mengembalikan URL authorization
+func startOIDCFlow(c *gin.Context, provider *oidc.Provider, linkUserID *uint) (string, error) {
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return "", apperror.Internal(err)
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		// IdP tidak bisa dihubungi atau discovery-nya tidak valid
+		return "", &apperror.Error{Status: http.StatusBadGateway, Code: apperror.CodeOIDCLoginFailed, Cause: err}
+	}
+
+	// State juga disimpan di cookie browser yang memulai alur. Callback tanpa cookie yang
+	// sama ditolak, sehingga authorization URL milik orang lain (misalnya link identity
+	// yang dikirim penyerang ke korban) tidak bisa diselesaikan di browser korban.
+	ttl := config.GetEnvDuration("OIDC_STATE_TTL", 10*time.Minute)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(ttl.Seconds()), oidcCookiePath, "", config.GetEnvBool("OIDC_COOKIE_SECURE", true), true)
+
+	record := models.OIDCLoginState{
+		StateHash:    models.HashToken(state),
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	if err := db(c).Create(&record).Error; err != nil {
+		return "", apperror.Internal(err)
+	}
+	return authURL, nil
+}
+
+// consumeOIDCState mengambil dan menghapus state agar callback yang sama tidak bisa diulang.
+// State harus sama dengan cookie dari startOIDCFlow di browser yang sama.
+func consumeOIDCState(c *gin.Context, provider, state string) (models.OIDCLoginState, error) {
+	var record models.OIDCLoginState
+	invalid := apperror.Unauthorized(apperror.CodeOIDCLoginFailed)
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", config.GetEnvBool("OIDC_COOKIE_SECURE", true), true)
+	if state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		return record, invalid
+	}
+
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ? AND provider = ?", models.HashToken(state), provider).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return invalid
+			}
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+	if err != nil {
+		return record, apperror.From(err)
+	}
+	if !time.Now().Before(record.ExpiresAt) {
+		return record, invalid
+	}
+	return record, nil
+}
+
+// resolveOIDCUser mencari user untuk identity: identity yang sudah tertaut, lalu email
+// terverifikasi (jika provider mengizinkan), lalu membuat user baru (jika diizinkan)
+func resolveOIDCUser(tx *gorm.DB, provider *oidc.Provider, claims *oidc.Claims) (models.User, error) {
+	var user models.User
+	now := time.Now()
+
+	var identity models.UserIdentity
+	err := tx.Where("provider = ? AND subject = ?", provider.Name, claims.Subject).First(&identity).Error
+	if err == nil {
+		if err := tx.First(&user, identity.UserID).Error; err != nil {
+			return user, apperror.NotFoundOr(err, apperror.CodeUserNotFound)
+		}
+		return user, tx.Model(&identity).Updates(map[string]interface{}{"last_login_at": now, "email": claims.Email}).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	linked := false
+	if provider.LinkByEmail && claims.EmailVerified && email != "" {
+		err := tx.Where("email = ?", email).First(&user).Error
+		if err == nil {
+			// Email lokal yang belum diverifikasi bisa saja didaftarkan orang lain, jadi
+			// identity hanya ditautkan ke akun yang sudah membuktikan kepemilikan email
+			if user.EmailVerifiedAt == nil {
+				return models.User{}, apperror.Forbidden(apperror.CodeOIDCAccountNotLinked)
+			}
+			linked = true
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, err
+		}
+	}
+
+	if !linked {
+		if !provider.AutoProvision {
+			return user, apperror.Forbidden(apperror.CodeOIDCAccountNotLinked)
+		}
+		var err error
+		if user, err = provisionOIDCUser(tx, provider, claims); err != nil {
+			return user, err
+		}
+	}
+
+	identity = models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    provider.Name,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	}
+	if err := tx.Create(&identity).Error; err != nil {
+		return user, err
+	}
+	slog.InfoContext(tx.Statement.Context, "Identity OIDC ditautkan", "provider", provider.Name, "user_id", user.ID, "provisioned", !linked)
+	return user, nil
+}
+
+// provisionOIDCUser membuat user baru tanpa password dari claim ID token.
+// Username diambil dari preferred_username atau email, ditambah angka jika sudah dipakai.
+func provisionOIDCUser(tx *gorm.DB, provider *oidc.Provider, claims *oidc.Claims) (models.User, error) {
+	base := oidcUsername(provider.Name, claims)
+	username := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+			return models.User{}, err
+		}
+		if count == 0 {
+			break
+		}
+		username = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	user := models.User{Username: username, DisplayName: claims.Name}
+	// Email hanya dipakai jika terverifikasi dan belum dipakai user lain
+	if claims.EmailVerified && claims.Email != "" {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("email = ?", claims.Email).Count(&count).Error; err != nil {
+			return user, err
+		}
+		if count == 0 {
+			now := time.Now()
+			user.Email = claims.Email
+			user.EmailVerifiedAt = &now
+		}
+	}
+	return user, tx.Create(&user).Error
+}
+
+func oidcUsername(provider string, claims *oidc.Claims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(claims.Email, "@")
+	}
+	candidate = strings.TrimSpace(candidate)
+	if len(candidate) < 3 {
+		candidate = provider + "-" + claims.Subject
+	}
+	// Sisakan ruang untuk akhiran angka; batas username sama dengan RegisterRequest
+	if runes := []rune(candidate); len(runes) > 45 {
+		candidate = string(runes[:45])
+	}
+	return candidate
+}
+
+// linkIdentity menautkan identity ke user yang memulai alur link
+func linkIdentity(c *gin.Context, provider string, claims *oidc.Claims, userID uint) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockUser(tx, userID); err != nil {
+			return err
+		}
+
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			if identity.UserID != userID {
+				return apperror.Conflict(apperror.CodeIdentityLinked)
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		identity = models.UserIdentity{UserID: userID, Provider: provider, Subject: claims.Subject, Email: claims.Email}
+		return tx.Create(&identity).Error
+	})
+	return identity, err
+}
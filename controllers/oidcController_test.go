@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+	"kanban/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupOIDCTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.UserIdentity{}, &models.OIDCLoginState{})
+
+	config.DB = db
+}
+
+func teardownOIDCTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE oidc_login_states")
+		config.DB.Exec("TRUNCATE TABLE user_identities")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+// mockIdP adalah identity provider OIDC minimal untuk test: discovery, JWKS dan token
+// endpoint yang memeriksa PKCE. Authorization code didaftarkan langsung oleh test.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+type mockGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp := &mockIdP{key: key, codes: map[string]mockGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		grant, ok := idp.codes[r.Form.Get("code")]
+		delete(idp.codes, r.Form.Get("code"))
+		idp.mu.Unlock()
+
+		if !ok || oidc.CodeChallenge(r.Form.Get("code_verifier")) != grant.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, grant.claims)
+		token.Header["kid"] = "test-key"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "unused", "token_type": "Bearer", "id_token": signed})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize meniru login user di IdP: mengembalikan code untuk authorization URL
+func (idp *mockIdP) authorize(authURL, subject string, extra jwt.MapClaims) (code, state string) {
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   query.Get("client_id"),
+		"sub":   subject,
+		"nonce": query.Get("nonce"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+
+	code = fmt.Sprintf("code-%d", time.Now().UnixNano())
+	idp.mu.Lock()
+	idp.codes[code] = mockGrant{challenge: query.Get("code_challenge"), claims: claims}
+	idp.mu.Unlock()
+	return code, query.Get("state")
+}
+
+func oidcTestRouter(idp *mockIdP) *gin.Engine {
+	oidc.Set(oidc.NewProvider("mock", idp.server.URL, "kanban-client", "secret", "http://localhost/auth/oidc/mock/callback", nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/auth/oidc/:provider/login", OIDCLogin)
+	router.GET("/auth/oidc/:provider/callback", OIDCCallback)
+	router.POST("/me/identities/:provider", middlewares.AuthMiddleware(), LinkMyIdentity)
+	return router
+}
+
+// oidcLogin menjalankan alur login sampai callback di "browser" yang sama: cookie state
+// dari response login ikut dikirim ke callback
+func oidcLogin(t *testing.T, router *gin.Engine, idp *mockIdP, subject string, claims jwt.MapClaims) (*httptest.ResponseRecorder, string, []*http.Cookie) {
+	req, _ := http.NewRequest("GET", "/auth/oidc/mock/login", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if !assert.Equal(t, http.StatusFound, resp.Code) {
+		return resp, "", nil
+	}
+
+	location := resp.Header().Get("Location")
+	assert.Contains(t, location, "code_challenge_method=S256")
+	cookies := resp.Result().Cookies()
+	code, state := idp.authorize(location, subject, claims)
+
+	callback := "/auth/oidc/mock/callback?" + url.Values{"code": {code}, "state": {state}}.Encode()
+	return oidcCallback(router, callback, cookies), callback, cookies
+}
+
+func oidcCallback(router *gin.Engine, callback string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", callback, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	setupOIDCTestDB()
+	defer teardownOIDCTestDB()
+
+	idp := newMockIdP(t)
+	router := oidcTestRouter(idp)
+	claims := jwt.MapClaims{"preferred_username": "jdoe", "email": "jdoe@corp.example", "email_verified": true, "name": "John Doe"}
+
+	resp, callback, cookies := oidcLogin(t, router, idp, "subject-1", claims)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NotEmpty(t, response["token"])
+
+	var user models.User
+	config.DB.Where("username = ?", "jdoe").First(&user)
+	assert.Equal(t, "jdoe@corp.example", user.Email)
+	assert.Equal(t, "John Doe", user.DisplayName)
+	assert.Empty(t, user.Password)
+
+	// State hanya bisa dipakai sekali
+	resp = oidcCallback(router, callback, cookies)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Login berikutnya memakai user yang sama lewat identity yang tertaut
+	resp, _, _ = oidcLogin(t, router, idp, "subject-1", claims)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var users, identities int64
+	config.DB.Model(&models.User{}).Count(&users)
+	config.DB.Model(&models.UserIdentity{}).Count(&identities)
+	assert.Equal(t, int64(1), users)
+	assert.Equal(t, int64(1), identities)
+}
+
+func TestOIDCLoginUsernameCollision(t *testing.T) {
+	setupOIDCTestDB()
+	defer teardownOIDCTestDB()
+
+	config.DB.Create(&models.User{Username: "jdoe", Password: "hashed", Email: "someone@example.com"})
+
+	idp := newMockIdP(t)
+	router := oidcTestRouter(idp)
+
+	resp, _, _ := oidcLogin(t, router, idp, "subject-2", jwt.MapClaims{"preferred_username": "jdoe", "email": "someone@example.com"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Email belum terverifikasi, jadi tidak ditautkan ke user yang sudah ada
+	var identity models.UserIdentity
+	config.DB.Where("subject = ?", "subject-2").First(&identity)
+	var user models.User
+	config.DB.First(&user, identity.UserID)
+	assert.Equal(t, "jdoe-2", user.Username)
+	assert.Empty(t, user.Email)
+}
+
+func TestOIDCLinkByEmailRequiresVerifiedLocalEmail(t *testing.T) {
+	setupOIDCTestDB()
+	defer teardownOIDCTestDB()
+
+	// Akun password dengan email korban yang tidak pernah diverifikasi
+	squatter := models.User{Username: "squatter", Password: "hashed", Email: "victim@corp.example"}
+	config.DB.Create(&squatter)
+
+	idp := newMockIdP(t)
+	router := oidcTestRouter(idp)
+	provider, _ := oidc.Get("mock")
+	provider.LinkByEmail = true
+
+	claims := jwt.MapClaims{"preferred_username": "victim", "email": "victim@corp.example", "email_verified": true}
+	resp, _, _ := oidcLogin(t, router, idp, "subject-victim", claims)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.UserIdentity{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// Setelah email lokal diverifikasi, identity boleh ditautkan
+	config.DB.Model(&squatter).Update("email_verified_at", time.Now())
+	resp, _, _ = oidcLogin(t, router, idp, "subject-victim", claims)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var identity models.UserIdentity
+	assert.NoError(t, config.DB.Where("subject = ?", "subject-victim").First(&identity).Error)
+	assert.Equal(t, squatter.ID, identity.UserID)
+}
+
+func TestOIDCCallbackRejectsInvalidIDToken(t *testing.T) {
+	setupOIDCTestDB()
+	defer teardownOIDCTestDB()
+
+	idp := newMockIdP(t)
+	router := oidcTestRouter(idp)
+
+	// ID token untuk client lain
+	resp, _, _ := oidcLogin(t, router, idp, "subject-3", jwt.MapClaims{"aud": "another-client"})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "oidc_login_failed", response["code"])
+
+	var users int64
+	config.DB.Model(&models.User{}).Count(&users)
+	assert.Equal(t, int64(0), users)
+}
+
+func TestOIDCLinkRequiresSameBrowser(t *testing.T) {
+	setupOIDCTestDB()
+	defer teardownOIDCTestDB()
+
+	attacker := models.User{Username: "attacker", Password: "hashed"}
+	config.DB.Create(&attacker)
+
+	idp := newMockIdP(t)
+	router := oidcTestRouter(idp)
+
+	resp, response := adminRequest(router, "POST", "/me/identities/mock", "attacker", nil)
+	if !assert.Equal(t, http.StatusOK, resp.Code) {
+		return
+	}
+	authURL, _ := response["authorization_url"].(string)
+	assert.NotEmpty(t, resp.Result().Cookies())
+
+	// Korban membuka authorization URL milik penyerang: callback di browser korban tidak
+	// membawa cookie state, jadi identity korban tidak tertaut ke akun penyerang
+	code, state := idp.authorize(authURL, "victim-subject", nil)
+	callback := "/auth/oidc/mock/callback?" + url.Values{"code": {code}, "state": {state}}.Encode()
+	resp = oidcCallback(router, callback, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	var identities int64
+	config.DB.Model(&models.UserIdentity{}).Count(&identities)
+	assert.Equal(t, int64(0), identities)
+
+	// Di browser yang memulai alur, identity tertaut seperti biasa
+	resp, response = adminRequest(router, "POST", "/me/identities/mock", "attacker", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	authURL, _ = response["authorization_url"].(string)
+	code, state = idp.authorize(authURL, "own-subject", nil)
+	callback = "/auth/oidc/mock/callback?" + url.Values{"code": {code}, "state": {state}}.Encode()
+	resp = oidcCallback(router, callback, resp.Result().Cookies())
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	config.DB.Model(&models.UserIdentity{}).Where("user_id = ?", attacker.ID).Count(&identities)
+	assert.Equal(t, int64(1), identities)
+}
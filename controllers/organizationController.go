@@ -0,0 +1,384 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/models"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateOrganization membuat organisasi baru dengan user yang sedang login sebagai admin-nya
+func CreateOrganization(c *gin.Context) {
+	var input models.CreateOrganizationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	org := models.Organization{Name: input.Name}
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: models.OrgRoleAdmin}).Error
+	})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": models.OrganizationWithRole{Organization: org, Role: models.OrgRoleAdmin}})
+}
+
+// GetMyOrganizations mendapatkan organisasi tempat user yang sedang login menjadi anggota
+func GetMyOrganizations(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	orgs := []models.OrganizationWithRole{}
+	if err := db(c).Model(&models.Organization{}).Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", user.ID).
+		Order("organizations.id").Scan(&orgs).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": orgs})
+}
+
+// GetOrganization mendapatkan detail organisasi beserta role user yang sedang login
+func GetOrganization(c *gin.Context) {
+	membership, err := orgMembership(c, db(c), false)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var org models.Organization
+	if err := db(c).First(&org, membership.OrganizationID).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeOrganizationNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.OrganizationWithRole{Organization: org, Role: membership.Role}})
+}
+
+// GetOrganizationMembers mendapatkan anggota organisasi beserta role-nya
+func GetOrganizationMembers(c *gin.Context) {
+	membership, err := orgMembership(c, db(c), false)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var members []models.OrganizationMember
+	if err := db(c).Where("organization_id = ?", membership.OrganizationID).
+		Preload("User").Order("id").Find(&members).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// UpdateOrganizationMember mengubah role anggota. Hanya admin organisasi, dan admin
+// terakhir tidak bisa diturunkan menjadi member.
+func UpdateOrganizationMember(c *gin.Context) {
+	var input models.OrganizationMemberRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	var member models.OrganizationMember
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		admin, err := orgMembership(c, tx, true)
+		if err != nil {
+			return err
+		}
+		if member, err = lockOrgMember(tx, admin.OrganizationID, c.Param("user_id")); err != nil {
+			return err
+		}
+
+		if member.Role == models.OrgRoleAdmin && input.Role != models.OrgRoleAdmin {
+			if err := ensureAnotherOrgAdmin(tx, member); err != nil {
+				return err
+			}
+		}
+		member.Role = input.Role
+		return tx.Model(&member).Update("role", member.Role).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveOrganizationMember mengeluarkan anggota dari organisasi. Admin bisa mengeluarkan
+// siapa saja dan setiap anggota bisa keluar sendiri. Anggota juga dikeluarkan dari project
+// organisasi dan task-nya di organisasi tersebut dilepas; pemilik project harus
+// dipindahkan dulu kepemilikannya.
+func RemoveOrganizationMember(c *gin.Context) {
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		self, err := orgMembership(c, tx, false)
+		if err != nil {
+			return err
+		}
+		member, err := lockOrgMember(tx, self.OrganizationID, c.Param("user_id"))
+		if err != nil {
+			return err
+		}
+		if member.UserID != self.UserID && self.Role != models.OrgRoleAdmin {
+			return apperror.Forbidden(apperror.CodeForbidden)
+		}
+		if member.Role == models.OrgRoleAdmin {
+			if err := ensureAnotherOrgAdmin(tx, member); err != nil {
+				return err
+			}
+		}
+
+		orgProjects := tx.Model(&models.Project{}).Select("id").Where("organization_id = ?", member.OrganizationID)
+
+		var owned []uint
+		if err := tx.Model(&models.Project{}).Where("organization_id = ? AND owner_id = ?", member.OrganizationID, member.UserID).
+			Order("id").Pluck("id", &owned).Error; err != nil {
+			return err
+		}
+		if len(owned) > 0 {
+			return apperror.Conflict(apperror.CodeUserOwnsProjects).
+				WithDetails(map[string]interface{}{"project_ids": owned})
+		}
+
+		if err := tx.Exec("DELETE FROM project_users WHERE user_id = ? AND project_id IN (?)", member.UserID, orgProjects).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Task{}).Where("assign_to = ? AND project_id IN (?)", member.UserID, orgProjects).
+			Update("assign_to", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&member).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// CreateOrganizationInvitation mengirim undangan bergabung ke email. Undangan hanya bisa
+// diterima oleh user dengan email yang sama, berlaku ORG_INVITATION_TTL (default 7 hari).
+func CreateOrganizationInvitation(c *gin.Context) {
+	var input models.CreateOrganizationInvitationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	if input.Role == "" {
+		input.Role = models.OrgRoleMember
+	}
+
+	admin, err := orgMembership(c, db(c), true)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var members int64
+	if err := db(c).Model(&models.OrganizationMember{}).
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ? AND users.email = ?", admin.OrganizationID, input.Email).
+		Count(&members).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if members > 0 {
+		c.Error(apperror.Conflict(apperror.CodeAlreadyMember))
+		return
+	}
+
+	var org models.Organization
+	if err := db(c).First(&org, admin.OrganizationID).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeOrganizationNotFound))
+		return
+	}
+
+	ttl := config.GetEnvDuration("ORG_INVITATION_TTL", 7*24*time.Hour)
+	invitation, token, err := models.NewOrganizationInvitation(org.ID, input.Email, input.Role, admin.UserID, ttl)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := db(c).Create(&invitation).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	link := config.GetEnv("ORG_INVITATION_URL", "http://localhost:8080/invitations/accept") + "?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to %s on Kanban", org.Name),
+		Body: fmt.Sprintf("Hi,\n\nYou have been invited to join %s as %s. Sign in with this email address and open the link below to accept. It expires in %s.\n\n%s\n",
+			org.Name, invitation.Role, ttl, link),
+	}
+	if err := mailer.Send(c.Request.Context(), msg); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Gagal mengirim email undangan organisasi", "invitation_id", invitation.ID, "error", err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": invitation})
+}
+
+// GetOrganizationInvitations mendapatkan undangan yang belum diterima
+func GetOrganizationInvitations(c *gin.Context) {
+	admin, err := orgMembership(c, db(c), true)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var invitations []models.OrganizationInvitation
+	if err := db(c).Where("organization_id = ? AND accepted_at IS NULL", admin.OrganizationID).
+		Order("id").Find(&invitations).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// RevokeOrganizationInvitation membatalkan undangan yang belum diterima
+func RevokeOrganizationInvitation(c *gin.Context) {
+	admin, err := orgMembership(c, db(c), true)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result := db(c).Where("id = ? AND organization_id = ? AND accepted_at IS NULL", c.Param("invitation_id"), admin.OrganizationID).
+		Delete(&models.OrganizationInvitation{})
+	if result.Error != nil {
+		c.Error(apperror.Internal(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.Error(apperror.NotFound(apperror.CodeNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptOrganizationInvitation menerima undangan organisasi untuk user yang sedang login
+func AcceptOrganizationInvitation(c *gin.Context) {
+	var input models.AcceptInvitationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var member models.OrganizationMember
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		var invitation models.OrganizationInvitation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", models.HashToken(input.Token)).First(&invitation).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeInvalidInvitation)
+		}
+		// Undangan terikat ke email tujuan. Email user bisa diubah sendiri lewat PATCH /me,
+		// jadi hanya email yang sudah diverifikasi yang dianggap cocok.
+		if !invitation.Usable(time.Now()) || user.Email == "" || !strings.EqualFold(user.Email, invitation.Email) {
+			return apperror.NotFound(apperror.CodeInvalidInvitation)
+		}
+		if user.EmailVerifiedAt == nil {
+			return apperror.Forbidden(apperror.CodeEmailNotVerified)
+		}
+
+		err := tx.Where("organization_id = ? AND user_id = ?", invitation.OrganizationID, user.ID).First(&member).Error
+		if err == nil {
+			return apperror.Conflict(apperror.CodeAlreadyMember)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		member = models.OrganizationMember{OrganizationID: invitation.OrganizationID, UserID: user.ID, Role: invitation.Role}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		return tx.Model(&invitation).Update("accepted_at", time.Now()).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// orgMembership mengambil keanggotaan user yang sedang login di organisasi :id.
+// Organisasi tempat user bukan anggota dibalas 404 agar keberadaannya tidak bocor;
+// adminOnly menolak member biasa dengan 403.
+func orgMembership(c *gin.Context, tx *gorm.DB, adminOnly bool) (models.OrganizationMember, error) {
+	var membership models.OrganizationMember
+	user, err := currentUser(c)
+	if err != nil {
+		return membership, err
+	}
+	if err := tx.Where("organization_id = ? AND user_id = ?", c.Param("id"), user.ID).First(&membership).Error; err != nil {
+		return membership, apperror.NotFoundOr(err, apperror.CodeOrganizationNotFound)
+	}
+	if adminOnly && membership.Role != models.OrgRoleAdmin {
+		return membership, apperror.Forbidden(apperror.CodeForbidden)
+	}
+	return membership, nil
+}
+
+// lockOrgMember mengambil keanggotaan user di organisasi dengan row lock
+func lockOrgMember(tx *gorm.DB, orgID uint, userID string) (models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error
+	if err != nil {
+		return member, apperror.NotFoundOr(err, apperror.CodeUserNotFound)
+	}
+	return member, nil
+}
+
+// ensureAnotherOrgAdmin memastikan organisasi tetap punya admin lain setelah member
+// berhenti menjadi admin. Baris admin dikunci agar dua request tidak saling meloloskan.
+func ensureAnotherOrgAdmin(tx *gorm.DB, member models.OrganizationMember) error {
+	var admins []uint
+	if err := tx.Model(&models.OrganizationMember{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ? AND id <> ?", member.OrganizationID, models.OrgRoleAdmin, member.ID).
+		Pluck("id", &admins).Error; err != nil {
+		return err
+	}
+	if len(admins) == 0 {
+		return apperror.Conflict(apperror.CodeLastOrganizationAdmin)
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupOrganizationTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{},
+		&models.Organization{}, &models.OrganizationMember{}, &models.OrganizationInvitation{}, &models.EmailVerificationToken{})
+
+	config.DB = db
+}
+
+func teardownOrganizationTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE email_verification_tokens")
+		config.DB.Exec("TRUNCATE TABLE organization_invitations")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+	mailer.Set(mailer.Console{})
+}
+
+func setupOrganizationRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+
+	auth := router.Group("/")
+	auth.Use(middlewares.AuthMiddleware(), middlewares.TenantScope())
+	auth.POST("/organizations", CreateOrganization)
+	auth.GET("/organizations", GetMyOrganizations)
+	auth.GET("/organizations/:id", GetOrganization)
+	auth.PUT("/organizations/:id/members/:user_id", UpdateOrganizationMember)
+	auth.DELETE("/organizations/:id/members/:user_id", RemoveOrganizationMember)
+	auth.POST("/organizations/:id/invitations", CreateOrganizationInvitation)
+	auth.POST("/invitations/accept", AcceptOrganizationInvitation)
+	auth.PATCH("/me", UpdateMe)
+	auth.POST("/me/email/verify", SendEmailVerification)
+	router.POST("/email/verify", VerifyEmail)
+	auth.POST("/projects", CreateProject)
+	auth.GET("/projects", GetAllProjects)
+	auth.GET("/projects/:id", GetProjects)
+	auth.GET("/tasks/:id", GetTasks)
+	auth.GET("/sprints", GetAllSprints)
+	auth.GET("/sprints/:id", GetSprint)
+	auth.GET("/sprints/:id/flow", GetSprintFlow)
+	auth.GET("/projects/:id/sprints", GetSprintsByProject)
+	auth.GET("/projects/:id/velocity", GetProjectVelocity)
+	auth.GET("/projects/:id/flow", GetProjectFlow)
+	auth.GET("/projects/:id/backlog", GetBacklog)
+	auth.GET("/projects/:id/workload", GetProjectWorkload)
+	return router
+}
+
+// seedTenant membuat organisasi berisi user yang diberikan untuk test handler yang
+// dibatasi tenant. Project test diisi OrganizationID organisasi ini.
+func seedTenant(users ...models.User) models.Organization {
+	org := models.Organization{Name: "Tenant"}
+	config.DB.Create(&org)
+	joinTenant(org.ID, users...)
+	return org
+}
+
+// joinTenant menambahkan user sebagai member organisasi
+func joinTenant(orgID uint, users ...models.User) {
+	for _, user := range users {
+		config.DB.Create(&models.OrganizationMember{OrganizationID: orgID, UserID: user.ID, Role: models.OrgRoleMember})
+	}
+}
+
+// asTenant menggantikan TenantScope di test yang memanggil handler tanpa AuthMiddleware
+func asTenant(orgIDs ...uint) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("organization_ids", orgIDs)
+		c.Next()
+	}
+}
+
+func TestOrganizationTenantIsolation(t *testing.T) {
+	setupOrganizationTestDB()
+	defer teardownOrganizationTestDB()
+
+	config.DB.Create(&models.User{Username: "alice", Password: "hashed"})
+	config.DB.Create(&models.User{Username: "bob", Password: "hashed"})
+	router := setupOrganizationRouter()
+
+	resp, response := adminRequest(router, "POST", "/organizations", "alice", map[string]string{"name": "Acme"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "admin", response["data"].(map[string]interface{})["role"])
+	acmeID := response["data"].(map[string]interface{})["id"].(float64)
+
+	resp, _ = adminRequest(router, "POST", "/organizations", "bob", map[string]string{"name": "Globex"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	// Organisasi tunggal dipakai jika organization_id kosong
+	resp, response = adminRequest(router, "POST", "/projects", "alice", map[string]string{"name": "Rocket"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, acmeID, response["data"].(map[string]interface{})["organization_id"])
+	projectID := uint(response["data"].(map[string]interface{})["ID"].(float64))
+
+	task := models.Task{Title: "Secret", Status: models.TaskStatusTodo, ProjectID: projectID}
+	config.DB.Create(&task)
+	sprint := models.Sprint{ProjectID: projectID, Name: "Sprint 1", EstimationType: models.EstimationTypeHour, Status: models.SprintStatusActive}
+	config.DB.Create(&sprint)
+
+	// Bob tidak melihat project maupun task milik Acme
+	resp, response = adminRequest(router, "GET", "/projects", "bob", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 0)
+
+	resp, response = adminRequest(router, "GET", fmt.Sprintf("/projects/%d", projectID), "bob", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "project_not_found", response["code"])
+
+	resp, _ = adminRequest(router, "GET", fmt.Sprintf("/tasks/%d", task.ID), "bob", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	for _, path := range []string{
+		fmt.Sprintf("/sprints/%d", sprint.ID),
+		fmt.Sprintf("/sprints/%d/flow", sprint.ID),
+		fmt.Sprintf("/projects/%d/sprints", projectID),
+		fmt.Sprintf("/projects/%d/velocity", projectID),
+		fmt.Sprintf("/projects/%d/flow", projectID),
+		fmt.Sprintf("/projects/%d/backlog", projectID),
+		fmt.Sprintf("/projects/%d/workload", projectID),
+	} {
+		resp, _ = adminRequest(router, "GET", path, "bob", nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, path)
+	}
+
+	resp, response = adminRequest(router, "GET", "/sprints", "bob", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 0)
+
+	resp, _ = adminRequest(router, "GET", fmt.Sprintf("/organizations/%d", int(acmeID)), "bob", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, response = adminRequest(router, "POST", "/projects", "bob", map[string]interface{}{"name": "Intrusion", "organization_id": acmeID})
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "organization_not_found", response["code"])
+
+	resp, response = adminRequest(router, "GET", "/projects", "alice", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+}
+
+func TestOrganizationInvitationFlow(t *testing.T) {
+	setupOrganizationTestDB()
+	defer teardownOrganizationTestDB()
+
+	outbox := &mailer.Memory{}
+	mailer.Set(outbox)
+
+	alice := models.User{Username: "alice", Password: "hashed", Email: "alice@example.com"}
+	carol := models.User{Username: "carol", Password: "hashed", Email: "carol@example.com"}
+	mallory := models.User{Username: "mallory", Password: "hashed", Email: "mallory@example.com"}
+	config.DB.Create(&alice)
+	config.DB.Create(&carol)
+	config.DB.Create(&mallory)
+	router := setupOrganizationRouter()
+
+	resp, response := adminRequest(router, "POST", "/organizations", "alice", map[string]string{"name": "Acme"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	orgID := int(response["data"].(map[string]interface{})["id"].(float64))
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/organizations/%d/invitations", orgID), "alice", map[string]string{"email": "alice@example.com"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp, response = adminRequest(router, "POST", fmt.Sprintf("/organizations/%d/invitations", orgID), "alice", map[string]string{"email": "Carol@example.com"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "member", response["data"].(map[string]interface{})["role"])
+
+	messages := outbox.Messages()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	assert.Equal(t, "Carol@example.com", messages[0].To)
+	match := resetTokenPattern.FindStringSubmatch(messages[0].Body)
+	if !assert.Len(t, match, 2) {
+		return
+	}
+	token, _ := url.QueryUnescape(match[1])
+
+	// Undangan hanya bisa diterima oleh email tujuan
+	resp, response = adminRequest(router, "POST", "/invitations/accept", "mallory", map[string]string{"token": token})
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "invalid_invitation", response["code"])
+
+	// Email yang belum diverifikasi tidak cukup untuk menerima undangan
+	resp, response = adminRequest(router, "POST", "/invitations/accept", "carol", map[string]string{"token": token})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "email_not_verified", response["code"])
+
+	resp, _ = adminRequest(router, "POST", "/me/email/verify", "carol", nil)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	messages = outbox.Messages()
+	if !assert.Len(t, messages, 2) {
+		return
+	}
+	match = resetTokenPattern.FindStringSubmatch(messages[1].Body)
+	if !assert.Len(t, match, 2) {
+		return
+	}
+	verifyToken, _ := url.QueryUnescape(match[1])
+	resp, _ = adminRequest(router, "POST", "/email/verify", "", map[string]string{"token": verifyToken})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, response = adminRequest(router, "POST", "/invitations/accept", "carol", map[string]string{"token": token})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "member", response["data"].(map[string]interface{})["role"])
+
+	// Undangan sekali pakai
+	resp, _ = adminRequest(router, "POST", "/invitations/accept", "carol", map[string]string{"token": token})
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, response = adminRequest(router, "GET", "/organizations", "carol", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+
+	// Member biasa tidak bisa mengundang
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/organizations/%d/invitations", orgID), "carol", map[string]string{"email": "dave@example.com"})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	// Link yang bocor tidak bisa dipakai hanya dengan mengganti email profil ke email tujuan
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/organizations/%d/invitations", orgID), "alice", map[string]string{"email": "dave@example.com"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	messages = outbox.Messages()
+	match = resetTokenPattern.FindStringSubmatch(messages[len(messages)-1].Body)
+	if !assert.Len(t, match, 2) {
+		return
+	}
+	token, _ = url.QueryUnescape(match[1])
+	config.DB.Model(&mallory).Update("email_verified_at", time.Now())
+	resp, _ = adminRequest(router, "PATCH", "/me", "mallory", map[string]string{"email": "dave@example.com"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp, response = adminRequest(router, "POST", "/invitations/accept", "mallory", map[string]string{"token": token})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "email_not_verified", response["code"])
+}
+
+func TestOrganizationLastAdmin(t *testing.T) {
+	setupOrganizationTestDB()
+	defer teardownOrganizationTestDB()
+
+	alice := models.User{Username: "alice", Password: "hashed"}
+	bob := models.User{Username: "bob", Password: "hashed"}
+	config.DB.Create(&alice)
+	config.DB.Create(&bob)
+	router := setupOrganizationRouter()
+
+	resp, response := adminRequest(router, "POST", "/organizations", "alice", map[string]string{"name": "Acme"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	orgID := uint(response["data"].(map[string]interface{})["id"].(float64))
+	config.DB.Create(&models.OrganizationMember{OrganizationID: orgID, UserID: bob.ID, Role: models.OrgRoleMember})
+
+	resp, response = adminRequest(router, "PUT", fmt.Sprintf("/organizations/%d/members/%d", orgID, alice.ID), "alice", map[string]string{"role": "member"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "last_organization_admin", response["code"])
+
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/organizations/%d/members/%d", orgID, alice.ID), "alice", nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// Member biasa tidak bisa mengeluarkan orang lain, tapi bisa keluar sendiri
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/organizations/%d/members/%d", orgID, alice.ID), "bob", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, _ = adminRequest(router, "PUT", fmt.Sprintf("/organizations/%d/members/%d", orgID, bob.ID), "alice", map[string]string{"role": "admin"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "PUT", fmt.Sprintf("/organizations/%d/members/%d", orgID, alice.ID), "alice", map[string]string{"role": "member"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/organizations/%d/members/%d", orgID, alice.ID), "alice", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var remaining int64
+	config.DB.Model(&models.OrganizationMember{}).Where("organization_id = ?", orgID).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/models"
+	"kanban/passwords"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ForgotPassword mengirim link reset password ke email user. Response selalu sama
+// baik email terdaftar maupun tidak, agar endpoint ini tidak bisa dipakai menebak akun.
+// Pencarian user, pembuatan token dan pengiriman email berjalan di background, sehingga
+// waktu response juga tidak membedakan keduanya.
+func ForgotPassword(c *gin.Context) {
+	var input models.ForgotPasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	mailer.Background(c.Request.Context(), "password_reset", func(ctx context.Context) error {
+		return sendPasswordReset(ctx, input.Email)
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// sendPasswordReset membuat token reset untuk user dengan email tersebut dan mengirim
+// link-nya. Email yang tidak terdaftar diabaikan tanpa error.
+func sendPasswordReset(ctx context.Context, email string) error {
+	database := config.DB.WithContext(ctx)
+
+	var user models.User
+	if err := database.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ttl := config.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	resetToken, token, err := models.NewPasswordResetToken(user.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		// Hanya token terbaru yang berlaku
+		if err := invalidateResetTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Create(&resetToken).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := config.GetEnv("PASSWORD_RESET_URL", "http://localhost:8080/password/reset") + "?token=" + url.QueryEscape(token)
+	return mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Kanban password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Username, ttl, link),
+	})
+}
+
+// ResetPassword memakai token reset untuk mengganti password, lalu mencabut semua sesi dan
+// token akses personal user. Reset password biasanya dipakai setelah akun diambil alih, jadi
+// token yang mungkin dibuat pihak lain tidak boleh tetap berlaku.
+func ResetPassword(c *gin.Context) {
+	var input models.ResetPasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	if !checkPasswordPolicy(c, "new_password", input.NewPassword) {
+		return
+	}
+
+	hashed, err := passwords.Hash(input.NewPassword)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		var resetToken models.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", models.HashToken(input.Token)).First(&resetToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.BadRequest(apperror.CodeInvalidResetToken)
+			}
+			return err
+		}
+		if !resetToken.Usable(time.Now()) {
+			return apperror.BadRequest(apperror.CodeInvalidResetToken)
+		}
+
+		if err := invalidateResetTokens(tx, resetToken.UserID); err != nil {
+			return err
+		}
+		var user models.User
+		if err := tx.Select("id", "username").First(&user, resetToken.UserID).Error; err != nil {
+			return err
+		}
+		// Pemilik email sudah terbukti, jadi lockout login akun ikut dibuka
+		if err := clearAccountThrottle(tx, user.Username); err != nil {
+			return err
+		}
+		if err := revokePersonalAccessTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"password":      hashed,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+// invalidateResetTokens menandai semua token reset user yang belum dipakai sebagai sudah dipakai
+func invalidateResetTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+// checkPasswordPolicy memvalidasi password baru terhadap policy dan daftar password bocor.
+// Jika gagal, error validasi untuk field didaftarkan ke context dan handler harus langsung return.
+func checkPasswordPolicy(c *gin.Context, field, plain string) bool {
+	violations, err := passwords.PolicyFromEnv().Check(plain)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	fields := make([]apperror.FieldError, len(violations))
+	for i, v := range violations {
+		fields[i] = apperror.FieldError{Field: field, Code: v.Code, Param: v.Param}
+	}
+	c.Error(apperror.Validation(fields...))
+	return false
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupPasswordTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.PasswordResetToken{}, &models.LoginThrottle{}, &models.PersonalAccessToken{})
+
+	config.DB = db
+}
+
+func teardownPasswordTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE personal_access_tokens")
+		config.DB.Exec("TRUNCATE TABLE password_reset_tokens")
+		config.DB.Exec("TRUNCATE TABLE login_throttles")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+	mailer.Set(mailer.Console{})
+}
+
+var resetTokenPattern = regexp.MustCompile(`token=([^\s]+)`)
+
+func passwordTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/password/forgot", ForgotPassword)
+	router.POST("/password/reset", ResetPassword)
+	router.GET("/me", middlewares.AuthMiddleware(), GetMe)
+	return router
+}
+
+func postJSON(router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	setupPasswordTestDB()
+	defer teardownPasswordTestDB()
+
+	outbox := &mailer.Memory{}
+	mailer.Set(outbox)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("oldpass123"), bcrypt.DefaultCost)
+	user := models.User{Username: "forgetful", Password: string(hashed), Email: "forgetful@example.com"}
+	config.DB.Create(&user)
+	oldToken, _ := middlewares.GenerateJWT(user.ID, user.Username, user.TokenVersion)
+	pat, patToken, _ := models.NewPersonalAccessToken(user.ID, "ci", []string{models.ScopeRead}, nil)
+	config.DB.Create(&pat)
+
+	router := passwordTestRouter()
+
+	resp := postJSON(router, "/password/forgot", map[string]string{"email": "forgetful@example.com"})
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	assert.NoError(t, mailer.Wait(context.Background()))
+	messages := outbox.Messages()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	assert.Equal(t, "forgetful@example.com", messages[0].To)
+	match := resetTokenPattern.FindStringSubmatch(messages[0].Body)
+	if !assert.Len(t, match, 2) {
+		return
+	}
+	token, _ := url.QueryUnescape(match[1])
+
+	// Token mentah tidak pernah disimpan
+	var stored models.PasswordResetToken
+	config.DB.Where("user_id = ?", user.ID).First(&stored)
+	assert.NotEqual(t, token, stored.TokenHash)
+	assert.Equal(t, models.HashToken(token), stored.TokenHash)
+
+	resp = postJSON(router, "/password/reset", map[string]string{"token": token, "new_password": "newpass456"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updated models.User
+	config.DB.First(&updated, user.ID)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte("newpass456")))
+	assert.Equal(t, 1, updated.TokenVersion)
+
+	// Token sekali pakai
+	resp = postJSON(router, "/password/reset", map[string]string{"token": token, "new_password": "another789"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Sesi lama dicabut
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", oldToken)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "session_revoked", response["code"])
+
+	// Token akses personal ikut dicabut
+	req, _ = http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", patToken)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	setupPasswordTestDB()
+	defer teardownPasswordTestDB()
+
+	outbox := &mailer.Memory{}
+	mailer.Set(outbox)
+
+	resp := postJSON(passwordTestRouter(), "/password/forgot", map[string]string{"email": "nobody@example.com"})
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.NoError(t, mailer.Wait(context.Background()))
+	assert.Empty(t, outbox.Messages())
+}
+
+func TestResetPasswordExpiredToken(t *testing.T) {
+	setupPasswordTestDB()
+	defer teardownPasswordTestDB()
+
+	user := models.User{Username: "late", Password: "hashed", Email: "late@example.com"}
+	config.DB.Create(&user)
+
+	resetToken, token, _ := models.NewPasswordResetToken(user.ID, time.Hour)
+	resetToken.ExpiresAt = time.Now().Add(-time.Minute)
+	config.DB.Create(&resetToken)
+
+	resp := postJSON(passwordTestRouter(), "/password/reset", map[string]string{"token": token, "new_password": "newpass456"})
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "invalid_reset_token", response["code"])
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetSprintCapacity mendapatkan entri kapasitas participant untuk sebuah sprint
+func GetSprintCapacity(c *gin.Context) {
+	var sprint models.Sprint
+	if err := db(c).Scopes(tenantRecords(c, "sprints")).First(&sprint, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeSprintNotFound))
+		return
+	}
+
+	var capacities []models.SprintCapacity
+	if err := db(c).Where("sprint_id = ?", sprint.ID).Order("user_id").Find(&capacities).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": capacities, "working_days": sprint.WorkingDays()})
+}
+
+// SetSprintCapacity menyimpan (upsert) kapasitas participant project untuk sprint yang belum selesai
+func SetSprintCapacity(c *gin.Context) {
+	var input models.SetSprintCapacityRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	var sprint models.Sprint
+	var plan models.SprintPlan
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockSprint(tx.Scopes(tenantRecords(c, "sprints")), c.Param("id"), &sprint); err != nil {
+			return err
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, &sprint.ID); err != nil {
+			return err
+		}
+		if sprint.Status == models.SprintStatusCompleted {
+			return apperror.Conflict(apperror.CodeSprintCompleted)
+		}
+
+		workingDays := sprint.WorkingDays()
+		userIDs := make([]uint, 0, len(input.Capacities))
+		var fields []apperror.FieldError
+		for _, entry := range input.Capacities {
+			userIDs = append(userIDs, entry.UserID)
+			if entry.DaysOff > workingDays {
+				fields = append(fields, apperror.FieldError{Field: "days_off", Code: "lte", Param: strconv.Itoa(workingDays)})
+			}
+		}
+
+		var participants []uint
+		if err := tx.Table("project_users").Where("project_id = ? AND user_id IN ?", sprint.ProjectID, userIDs).
+			Pluck("user_id", &participants).Error; err != nil {
+			return err
+		}
+		if len(participants) != countUnique(userIDs) {
+			fields = append(fields, apperror.FieldError{Field: "user_id", Code: "participant"})
+		}
+		if len(fields) > 0 {
+			return apperror.Validation(fields...)
+		}
+
+		capacities := make([]models.SprintCapacity, 0, len(input.Capacities))
+		for _, entry := range input.Capacities {
+			capacities = append(capacities, models.SprintCapacity{
+				SprintID: sprint.ID,
+				UserID:   entry.UserID,
+				Capacity: entry.Capacity,
+				DaysOff:  entry.DaysOff,
+			})
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "sprint_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"capacity", "days_off", "updated_at"}),
+		}).Create(&capacities).Error; err != nil {
+			return err
+		}
+
+		var err error
+		plan, err = sprintPlan(tx, &sprint)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// GetSprintPlanning membandingkan estimasi task yang di-commit per assignee dengan kapasitasnya
+func GetSprintPlanning(c *gin.Context) {
+	var sprint models.Sprint
+	if err := db(c).Scopes(tenantRecords(c, "sprints")).First(&sprint, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeSprintNotFound))
+		return
+	}
+
+	plan, err := sprintPlan(db(c), &sprint)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// sprintPlan memuat tasks, kapasitas dan username participant lalu menghitung rencana sprint
+func sprintPlan(tx *gorm.DB, sprint *models.Sprint) (models.SprintPlan, error) {
+	if err := tx.Where("sprint_id = ?", sprint.ID).Find(&sprint.Tasks).Error; err != nil {
+		return models.SprintPlan{}, err
+	}
+
+	var capacities []models.SprintCapacity
+	if err := tx.Where("sprint_id = ?", sprint.ID).Find(&capacities).Error; err != nil {
+		return models.SprintPlan{}, err
+	}
+
+	userIDs := make([]uint, 0, len(capacities)+len(sprint.Tasks))
+	for _, capacity := range capacities {
+		userIDs = append(userIDs, capacity.UserID)
+	}
+	for _, task := range sprint.Tasks {
+		if task.AssignTo != nil {
+			userIDs = append(userIDs, *task.AssignTo)
+		}
+	}
+
+	usernames := map[uint]string{}
+	if len(userIDs) > 0 {
+		var users []models.User
+		if err := tx.Select("id", "username").Find(&users, userIDs).Error; err != nil {
+			return models.SprintPlan{}, err
+		}
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+	}
+
+	return models.BuildSprintPlan(sprint, capacities, usernames), nil
+}
+
+func countUnique(ids []uint) int {
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	return len(seen)
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupPlanningTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{}, &models.SprintCapacity{},
+		&models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownPlanningTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE sprint_capacities")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+// createPlanningFixture membuat project dengan dua participant dan sprint dua minggu (10 hari kerja)
+func createPlanningFixture() (models.Project, models.User, models.User, models.Sprint) {
+	alice := models.User{Username: "alice", Password: "hashed", Email: "alice@example.com"}
+	bob := models.User{Username: "bob", Password: "hashed", Email: "bob@example.com"}
+	config.DB.Create(&alice)
+	config.DB.Create(&bob)
+
+	org := seedTenant(alice, bob)
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID, UserParticipants: []models.User{alice, bob}}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+		EndDate:        time.Date(2024, 1, 12, 0, 0, 0, 0, time.Local),
+		Status:         "planned",
+	}
+	config.DB.Create(&sprint)
+
+	return project, alice, bob, sprint
+}
+
+func TestSetSprintCapacityAndPlanning(t *testing.T) {
+	setupPlanningTestDB()
+	defer teardownPlanningTestDB()
+
+	project, alice, bob, sprint := createPlanningFixture()
+	config.DB.Create(&models.Task{Title: "A", Status: "todo", SprintID: &sprint.ID, AssignTo: &alice.ID, Estimation: 30})
+	config.DB.Create(&models.Task{Title: "B", Status: "todo", SprintID: &sprint.ID, AssignTo: &bob.ID, Estimation: 10})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(*project.OrganizationID))
+	router.PUT("/sprints/:id/capacity", SetSprintCapacity)
+	router.GET("/sprints/:id/planning", GetSprintPlanning)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"capacities": []map[string]interface{}{
+			{"user_id": alice.ID, "capacity": 40, "days_off": 5},
+			{"user_id": bob.ID, "capacity": 40},
+		},
+	})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/sprints/%d/capacity", sprint.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.SprintCapacity{}).Where("sprint_id = ?", sprint.ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/sprints/%d/planning", sprint.ID), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data models.SprintPlan `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	plan := response.Data
+	assert.Equal(t, 10, plan.WorkingDays)
+	assert.Equal(t, 60.0, plan.TotalCapacity)
+	assert.Equal(t, 40.0, plan.TotalCommitted)
+	assert.False(t, plan.OverCommitted)
+	if assert.Len(t, plan.Members, 2) {
+		assert.Equal(t, 20.0, plan.Members[0].EffectiveCapacity)
+		assert.True(t, plan.Members[0].OverCommitted)
+		assert.False(t, plan.Members[1].OverCommitted)
+	}
+	if assert.Len(t, plan.Warnings, 1) {
+		assert.Equal(t, models.PlanningWarningMemberOverCommitted, plan.Warnings[0].Code)
+		assert.Equal(t, alice.ID, *plan.Warnings[0].UserID)
+	}
+}
+
+func TestSetSprintCapacityRejectsNonParticipant(t *testing.T) {
+	setupPlanningTestDB()
+	defer teardownPlanningTestDB()
+
+	project, _, _, sprint := createPlanningFixture()
+	outsider := models.User{Username: "outsider", Password: "hashed", Email: "outsider@example.com"}
+	config.DB.Create(&outsider)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(*project.OrganizationID))
+	router.PUT("/sprints/:id/capacity", SetSprintCapacity)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"capacities": []map[string]interface{}{{"user_id": outsider.ID, "capacity": 40}},
+	})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/sprints/%d/capacity", sprint.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.SprintCapacity{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestGetSprintPlanningWarnsWhenSprintOverCommitted(t *testing.T) {
+	setupPlanningTestDB()
+	defer teardownPlanningTestDB()
+
+	project, alice, _, sprint := createPlanningFixture()
+	config.DB.Create(&models.SprintCapacity{SprintID: sprint.ID, UserID: alice.ID, Capacity: 10})
+	config.DB.Create(&models.Task{Title: "Big", Status: "todo", SprintID: &sprint.ID, Estimation: 15})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(*project.OrganizationID))
+	router.GET("/sprints/:id/planning", GetSprintPlanning)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/sprints/%d/planning", sprint.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data models.SprintPlan `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.True(t, response.Data.OverCommitted)
+
+	codes := []string{}
+	for _, warning := range response.Data.Warnings {
+		codes = append(codes, warning.Code)
+	}
+	assert.Contains(t, codes, models.PlanningWarningSprintOverCommitted)
+	assert.Contains(t, codes, models.PlanningWarningUnassignedWork)
+}
@@ -2,25 +2,18 @@ package controllers
 
 import (
 	"fmt"
-	"kanban/config"
+	"kanban/apperror"
 	"kanban/models"
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func CreateProject(c *gin.Context) {
-	type CreateProjectInput struct {
-		Name           string `json:"name"`
-		Description    string `json:"description"`
-		Hours          int    `json:"hours"`
-		StoryPoints    int    `json:"story_points"`
-		ParticipantIDs []uint `json:"participant_ids"`
-	}
-
-	var input CreateProjectInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.CreateProjectRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
@@ -28,22 +21,47 @@ func CreateProject(c *gin.Context) {
 		Name:        input.Name,
 		Description: input.Description,
 	}
+	if userID := c.GetUint("user_id"); userID != 0 {
+		project.OwnerID = &userID
+	}
 
-	if err := config.DB.Create(&project).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Project dibuat di organisasi user; organization_id boleh kosong jika user hanya
+	// tergabung di satu organisasi
+	orgID := input.OrganizationID
+	ids := tenantOrganizationIDs(c)
+	if orgID == 0 && len(ids) == 1 {
+		orgID = ids[0]
+	}
+	if orgID == 0 {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "organization_id", Code: "required"}))
 		return
 	}
-
-	if input.Name == "" || input.Description == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields"})
+	if !slices.Contains(ids, orgID) {
+		c.Error(apperror.NotFound(apperror.CodeOrganizationNotFound))
 		return
 	}
+	project.OrganizationID = &orgID
+
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgMembers(tx, project.OrganizationID, "participant_ids", input.ParticipantIDs...); err != nil {
+			return err
+		}
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		if len(input.ParticipantIDs) == 0 {
+			return nil
+		}
 
-	if len(input.ParticipantIDs) > 0 {
 		var participants []models.User
-		if err := config.DB.Find(&participants, input.ParticipantIDs).Error; err == nil {
-			config.DB.Model(&project).Association("UserParticipants").Append(participants)
+		if err := tx.Find(&participants, input.ParticipantIDs).Error; err != nil {
+			return err
 		}
+		return tx.Model(&project).Association("UserParticipants").Append(participants)
+	})
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": project})
@@ -53,18 +71,25 @@ func GetProjects(c *gin.Context) {
 	id := c.Param("id")
 
 	var project models.Project
-	if err := config.DB.Preload("UserParticipants").First(&project, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	if err := db(c).Scopes(tenantProjects(c)).Preload("UserParticipants").First(&project, id).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": project})
 }
 
+// GetAllProjects mendapatkan project yang terlihat oleh user. Project yang diarsipkan
+// hanya ikut jika include_archived=true.
 func GetAllProjects(c *gin.Context) {
+	var query models.ArchiveQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
 	var projects []models.Project
-	if err := config.DB.Preload("UserParticipants").Find(&projects).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db(c).Scopes(tenantProjects(c), withoutArchived("projects", query)).Preload("UserParticipants").Find(&projects).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
@@ -74,32 +99,47 @@ func GetAllProjects(c *gin.Context) {
 func AddParticipant(c *gin.Context) {
 	projectID := c.Param("id")
 
-	var input struct {
-		UserID uint `json:"user_id"`
-	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.AddParticipantRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	var project models.Project
-	if err := config.DB.First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, projectID).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+	if err := writableProject(&project); err != nil {
+		c.Error(err)
 		return
 	}
 
+	// Keanggotaan dicek lebih dulu agar user organisasi lain tidak bisa dibedakan dari
+	// user yang tidak ada
+	if err := requireOrgMembers(db(c), project.OrganizationID, "user_id", input.UserID); err != nil {
+		c.Error(err)
+		return
+	}
 	var user models.User
-	if err := config.DB.First(&user, input.UserID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if err := db(c).First(&user, input.UserID).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeUserNotFound))
+		return
+	}
+	if user.DisabledAt != nil {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "user_id", Code: "userdisabled"}))
+		return
+	}
+	if project.RequireTwoFactor && !user.TOTPEnabled {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "user_id", Code: "twofactor"}))
 		return
 	}
 
-	if err := config.DB.Model(&project).Association("UserParticipants").Append(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db(c).Model(&project).Association("UserParticipants").Append(&user); err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
-	config.DB.Preload("UserParticipants").First(&project, project.ID)
+	db(c).Preload("UserParticipants").First(&project, project.ID)
 	c.JSON(http.StatusOK, gin.H{"data": project})
 }
 
@@ -111,23 +151,68 @@ func RemoveParticipant(c *gin.Context) {
 
 	var projectID, userID uint
 	if _, err := fmt.Sscanf(projectIDStr, "%d", &projectID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		c.Error(apperror.BadRequest(apperror.CodeInvalidID))
 		return
 	}
 	if _, err := fmt.Sscanf(userIDStr, "%d", &userID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperror.BadRequest(apperror.CodeInvalidID))
+		return
+	}
+
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, projectID).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+	if err := writableProject(&project); err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := config.DB.First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	if project.OwnerID != nil && *project.OwnerID == userID {
+		c.Error(apperror.Conflict(apperror.CodeProjectOwner))
 		return
 	}
 
-	if err := config.DB.Model(&project).Association("UserParticipants").Delete(&models.User{ID: userID}); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove participant"})
+	if err := db(c).Model(&project).Association("UserParticipants").Delete(&models.User{ID: userID}); err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Participant removed successfully"})
 }
+
+// GetProjectWorkload mendapatkan jumlah task terbuka dan sisa estimasi per participant project
+func GetProjectWorkload(c *gin.Context) {
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).Preload("UserParticipants").First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+
+	var tasks []models.Task
+	if err := db(c).Where("project_id = ? AND status <> ?", project.ID, models.TaskStatusDone).Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	// Username untuk assignee yang sudah bukan participant project
+	var assigneeIDs []uint
+	for _, task := range tasks {
+		if task.AssignTo != nil {
+			assigneeIDs = append(assigneeIDs, *task.AssignTo)
+		}
+	}
+	assignees := map[uint]string{}
+	if len(assigneeIDs) > 0 {
+		var users []models.User
+		if err := db(c).Select("id", "username").Find(&users, assigneeIDs).Error; err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		for _, user := range users {
+			assignees[user.ID] = user.Username
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.BuildProjectWorkload(project.UserParticipants, assignees, tasks)})
+}
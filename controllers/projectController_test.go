@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"kanban/config"
+	"kanban/middlewares"
 	"kanban/models"
 
 	"github.com/gin-gonic/gin"
@@ -43,7 +44,7 @@ func setupProjectTestDB() {
 		log.Fatal("Failed to connect test database:", err)
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Task{})
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Task{}, &models.Organization{}, &models.OrganizationMember{})
 
 	config.DB = db
 }
@@ -55,6 +56,8 @@ func teardownProjectTestDB() {
 		config.DB.Exec("TRUNCATE TABLE project_users")
 		config.DB.Exec("TRUNCATE TABLE projects")
 		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
 		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
 
 		sqlDB, _ := config.DB.DB()
@@ -81,9 +84,11 @@ func TestCreateProject(t *testing.T) {
 	}
 	config.DB.Create(&user1)
 	config.DB.Create(&user2)
+	org := seedTenant(user1, user2)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/projects", CreateProject)
 	projectData := map[string]any{
 		"name":            "Test Project",
@@ -118,8 +123,11 @@ func TestCreateProjectWithoutParticipants(t *testing.T) {
 	setupProjectTestDB()
 	defer teardownProjectTestDB()
 
+	org := seedTenant()
+
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/projects", CreateProject)
 
 	projectData := map[string]interface{}{
@@ -150,6 +158,7 @@ func TestCreateProjectInvalidJSON(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/projects", CreateProject)
 
 	req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString("invalid json"))
@@ -167,6 +176,7 @@ func TestCreateProjectMissingRequiredField(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/projects", CreateProject)
 
 	projectData := map[string]interface{}{
@@ -182,25 +192,33 @@ func TestCreateProjectMissingRequiredField(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.Project{}).Count(&count)
+	assert.Equal(t, int64(0), count)
 }
 
 func TestGetAllProjects(t *testing.T) {
 	setupProjectTestDB()
 	defer teardownProjectTestDB()
 
+	org := seedTenant()
 	project1 := models.Project{
-		Name:        "Project 1",
-		Description: "First project",
+		Name:           "Project 1",
+		Description:    "First project",
+		OrganizationID: &org.ID,
 	}
 	project2 := models.Project{
-		Name:        "Project 2",
-		Description: "Second project",
+		Name:           "Project 2",
+		Description:    "Second project",
+		OrganizationID: &org.ID,
 	}
 	config.DB.Create(&project1)
 	config.DB.Create(&project2)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/projects", GetAllProjects)
 
 	req, _ := http.NewRequest("GET", "/projects", nil)
@@ -219,8 +237,11 @@ func TestGetAllProjectsEmpty(t *testing.T) {
 	setupProjectTestDB()
 	defer teardownProjectTestDB()
 
+	org := seedTenant()
+
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/projects", GetAllProjects)
 
 	req, _ := http.NewRequest("GET", "/projects", nil)
@@ -245,15 +266,18 @@ func TestGetProject(t *testing.T) {
 	}
 	config.DB.Create(&user)
 
+	org := seedTenant(user)
 	project := models.Project{
-		Name:        "Test Project",
-		Description: "Get project test",
+		Name:           "Test Project",
+		Description:    "Get project test",
+		OrganizationID: &org.ID,
 	}
 	config.DB.Create(&project)
 	config.DB.Model(&project).Association("UserParticipants").Append(&user)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/projects/:id", GetProjects)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d", project.ID), nil)
@@ -272,8 +296,11 @@ func TestGetProjectNotFound(t *testing.T) {
 	setupProjectTestDB()
 	defer teardownProjectTestDB()
 
+	org := seedTenant()
+
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/projects/:id", GetProjects)
 
 	req, _ := http.NewRequest("GET", "/projects/99999", nil)
@@ -294,13 +321,16 @@ func TestAddParticipant(t *testing.T) {
 	}
 	config.DB.Create(&user)
 
+	org := seedTenant(user)
 	project := models.Project{
-		Name: "Test Project",
+		Name:           "Test Project",
+		OrganizationID: &org.ID,
 	}
 	config.DB.Create(&project)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/projects/:id/participants", AddParticipant)
 
 	participantData := map[string]uint{
@@ -332,14 +362,17 @@ func TestRemoveParticipant(t *testing.T) {
 	}
 	config.DB.Create(&user)
 
+	org := seedTenant(user)
 	project := models.Project{
-		Name: "Test Project",
+		Name:           "Test Project",
+		OrganizationID: &org.ID,
 	}
 	config.DB.Create(&project)
 	config.DB.Model(&project).Association("UserParticipants").Append(&user)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.DELETE("/projects/:id/participants/:user_id", RemoveParticipant)
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/projects/%d/participants/%d", project.ID, user.ID), nil)
@@ -352,3 +385,51 @@ func TestRemoveParticipant(t *testing.T) {
 	config.DB.Preload("UserParticipants").First(&updatedProject, project.ID)
 	assert.Equal(t, 0, len(updatedProject.UserParticipants))
 }
+
+func TestGetProjectWorkload(t *testing.T) {
+	setupProjectTestDB()
+	defer teardownProjectTestDB()
+
+	alice := models.User{Username: "alice", Password: "hashed", Email: "alice@example.com"}
+	bob := models.User{Username: "bob", Password: "hashed", Email: "bob@example.com"}
+	config.DB.Create(&alice)
+	config.DB.Create(&bob)
+
+	org := seedTenant(alice, bob)
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID, UserParticipants: []models.User{alice, bob}}
+	config.DB.Create(&project)
+
+	config.DB.Create(&models.Task{Title: "A1", Status: "todo", ProjectID: project.ID, AssignTo: &alice.ID, Estimation: 3})
+	config.DB.Create(&models.Task{Title: "A2", Status: "in_progress", ProjectID: project.ID, AssignTo: &alice.ID, Estimation: 5})
+	config.DB.Create(&models.Task{Title: "A3", Status: "done", ProjectID: project.ID, AssignTo: &alice.ID, Estimation: 8})
+	config.DB.Create(&models.Task{Title: "Unassigned", Status: "todo", ProjectID: project.ID, Estimation: 2})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/projects/:id/workload", GetProjectWorkload)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/workload", project.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data []models.MemberWorkload `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+
+	if assert.Len(t, response.Data, 3) {
+		assert.Equal(t, "alice", response.Data[0].Username)
+		assert.Equal(t, 2, response.Data[0].OpenTaskCount)
+		assert.Equal(t, 1, response.Data[0].InProgressCount)
+		assert.Equal(t, 8.0, response.Data[0].RemainingEstimation)
+
+		assert.Equal(t, "bob", response.Data[1].Username)
+		assert.Equal(t, 0, response.Data[1].OpenTaskCount)
+
+		assert.Nil(t, response.Data[2].UserID)
+		assert.Equal(t, 1, response.Data[2].OpenTaskCount)
+	}
+}
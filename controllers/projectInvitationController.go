@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"fmt"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/models"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateProjectInvitation membuat undangan project lewat email atau link yang bisa
+// dibagikan. Hanya pemilik project dan participant dengan role maintainer.
+func CreateProjectInvitation(c *gin.Context) {
+	var input models.CreateProjectInvitationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	if input.Role == "" {
+		input.Role = models.ProjectRoleMember
+	}
+	maxUses := 1
+	if input.MaxUses != nil {
+		maxUses = *input.MaxUses
+	}
+	if input.Email != "" && maxUses != 1 {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "max_uses", Code: "singleuse"}))
+		return
+	}
+
+	project, inviterID, err := projectInvitationManager(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writableProject(&project); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if input.Email != "" {
+		var participants int64
+		if err := db(c).Model(&models.ProjectUser{}).
+			Joins("JOIN users ON users.id = project_users.user_id").
+			Where("project_users.project_id = ? AND users.email = ?", project.ID, input.Email).
+			Count(&participants).Error; err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if participants > 0 {
+			c.Error(apperror.Conflict(apperror.CodeAlreadyParticipant))
+			return
+		}
+	}
+
+	ttl := config.GetEnvDuration("PROJECT_INVITATION_TTL", 7*24*time.Hour)
+	if input.ExpiresInDays != nil {
+		ttl = time.Duration(*input.ExpiresInDays) * 24 * time.Hour
+	}
+	invitation, token, err := models.NewProjectInvitation(project.ID, input.Email, input.Role, maxUses, inviterID, ttl)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := db(c).Create(&invitation).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	link := config.GetEnv("PROJECT_INVITATION_URL", "http://localhost:8080/project-invitations/accept") + "?token=" + url.QueryEscape(token)
+	if invitation.Email == "" {
+		// Link hanya ditampilkan sekali ke pembuatnya; yang tersimpan hanya hash token
+		c.JSON(http.StatusCreated, gin.H{"data": invitation, "url": link})
+		return
+	}
+
+	msg := mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to the project %s on Kanban", project.Name),
+		Body: fmt.Sprintf("Hi,\n\nYou have been invited to join the project %s as %s. Sign in with this email address and open the link below to accept. It expires in %s.\n\n%s\n",
+			project.Name, invitation.Role, ttl, link),
+	}
+	if err := mailer.Send(c.Request.Context(), msg); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Gagal mengirim email undangan project", "invitation_id", invitation.ID, "error", err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": invitation})
+}
+
+// GetProjectInvitations mendapatkan undangan project yang masih bisa dipakai
+func GetProjectInvitations(c *gin.Context) {
+	project, _, err := projectInvitationManager(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var invitations []models.ProjectInvitation
+	if err := db(c).Where("project_id = ? AND expires_at > ? AND (max_uses = 0 OR use_count < max_uses)", project.ID, time.Now()).
+		Order("id").Find(&invitations).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// RevokeProjectInvitation membatalkan undangan project
+func RevokeProjectInvitation(c *gin.Context) {
+	project, _, err := projectInvitationManager(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writableProject(&project); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result := db(c).Where("id = ? AND project_id = ?", c.Param("invitation_id"), project.ID).Delete(&models.ProjectInvitation{})
+	if result.Error != nil {
+		c.Error(apperror.Internal(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.Error(apperror.NotFound(apperror.CodeNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptProjectInvitation menambahkan user yang sedang login sebagai participant project
+// sesuai undangan. Syarat participant biasa tetap berlaku: anggota organisasi project dan
+// 2FA aktif jika project mewajibkannya.
+func AcceptProjectInvitation(c *gin.Context) {
+	var input models.AcceptInvitationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var project models.Project
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		var invitation models.ProjectInvitation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", models.HashToken(input.Token)).First(&invitation).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeInvalidInvitation)
+		}
+		if !invitation.Usable(time.Now()) {
+			return apperror.NotFound(apperror.CodeInvalidInvitation)
+		}
+		// Sama seperti undangan organisasi, email tujuan hanya cocok jika sudah diverifikasi
+		if invitation.Email != "" {
+			if user.Email == "" || !strings.EqualFold(user.Email, invitation.Email) {
+				return apperror.NotFound(apperror.CodeInvalidInvitation)
+			}
+			if user.EmailVerifiedAt == nil {
+				return apperror.Forbidden(apperror.CodeEmailNotVerified)
+			}
+		}
+
+		if err := tx.First(&project, invitation.ProjectID).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeInvalidInvitation)
+		}
+		if err := writableProject(&project); err != nil {
+			return err
+		}
+		if err := requireOrgMembers(tx, project.OrganizationID, "token", user.ID); err != nil {
+			return err
+		}
+		if project.RequireTwoFactor && !user.TOTPEnabled {
+			return apperror.Validation(apperror.FieldError{Field: "token", Code: "twofactor"})
+		}
+
+		var participants int64
+		if err := tx.Model(&models.ProjectUser{}).Where("project_id = ? AND user_id = ?", project.ID, user.ID).
+			Count(&participants).Error; err != nil {
+			return err
+		}
+		if participants > 0 {
+			return apperror.Conflict(apperror.CodeAlreadyParticipant)
+		}
+
+		if err := tx.Create(&models.ProjectUser{ProjectID: project.ID, UserID: user.ID, Role: invitation.Role}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&invitation).Update("use_count", gorm.Expr("use_count + 1")).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db(c).Preload("UserParticipants").First(&project, project.ID)
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// projectInvitationManager mengambil project :id dan memastikan user yang sedang login
+// adalah pemiliknya atau participant dengan role maintainer
+func projectInvitationManager(c *gin.Context) (models.Project, uint, error) {
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		return project, 0, apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return project, 0, err
+	}
+	if project.OwnerID != nil && *project.OwnerID == user.ID {
+		return project, user.ID, nil
+	}
+
+	var maintainers int64
+	if err := db(c).Model(&models.ProjectUser{}).
+		Where("project_id = ? AND user_id = ? AND role = ?", project.ID, user.ID, models.ProjectRoleMaintainer).
+		Count(&maintainers).Error; err != nil {
+		return project, 0, apperror.Internal(err)
+	}
+	if maintainers == 0 {
+		return project, 0, apperror.Forbidden(apperror.CodeForbidden)
+	}
+	return project, user.ID, nil
+}
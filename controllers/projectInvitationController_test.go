@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/mailer"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupProjectInvitationTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.ProjectUser{}, &models.ProjectInvitation{},
+		&models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownProjectInvitationTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE project_invitations")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+	mailer.Set(mailer.Console{})
+}
+
+func setupProjectInvitationRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+
+	auth := router.Group("/")
+	auth.Use(middlewares.AuthMiddleware(), middlewares.TenantScope())
+	auth.POST("/projects/:id/invitations", CreateProjectInvitation)
+	auth.GET("/projects/:id/invitations", GetProjectInvitations)
+	auth.DELETE("/projects/:id/invitations/:invitation_id", RevokeProjectInvitation)
+	auth.POST("/project-invitations/accept", AcceptProjectInvitation)
+	return router
+}
+
+// seedInvitationProject membuat organisasi berisi user yang diberikan dan project milik owner
+func seedInvitationProject(owner models.User, members ...models.User) models.Project {
+	org := models.Organization{Name: "Acme"}
+	config.DB.Create(&org)
+	for _, user := range append(members, owner) {
+		config.DB.Create(&models.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: models.OrgRoleMember})
+	}
+	project := models.Project{Name: "Rocket", OrganizationID: &org.ID, OwnerID: &owner.ID, UserParticipants: []models.User{owner}}
+	config.DB.Create(&project)
+	return project
+}
+
+func TestProjectInvitationLink(t *testing.T) {
+	setupProjectInvitationTestDB()
+	defer teardownProjectInvitationTestDB()
+
+	owner := models.User{Username: "owner", Password: "hashed"}
+	first := models.User{Username: "first", Password: "hashed"}
+	second := models.User{Username: "second", Password: "hashed"}
+	outsider := models.User{Username: "outsider", Password: "hashed"}
+	config.DB.Create(&owner)
+	config.DB.Create(&first)
+	config.DB.Create(&second)
+	config.DB.Create(&outsider)
+	project := seedInvitationProject(owner, first, second)
+	router := setupProjectInvitationRouter()
+
+	// Participant biasa tidak bisa mengundang
+	resp, _ := adminRequest(router, "POST", fmt.Sprintf("/projects/%d/invitations", project.ID), "first", map[string]int{"max_uses": 1})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, response := adminRequest(router, "POST", fmt.Sprintf("/projects/%d/invitations", project.ID), "owner",
+		map[string]interface{}{"max_uses": 1, "role": "maintainer"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	link, _ := response["url"].(string)
+	token, _ := url.QueryUnescape(link[strings.Index(link, "token=")+len("token="):])
+
+	// User di luar organisasi project ditolak
+	resp, _ = adminRequest(router, "POST", "/project-invitations/accept", "outsider", map[string]string{"token": token})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp, response = adminRequest(router, "POST", "/project-invitations/accept", "first", map[string]string{"token": token})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"].(map[string]interface{})["UserParticipants"], 2)
+
+	var membership models.ProjectUser
+	config.DB.Where("project_id = ? AND user_id = ?", project.ID, first.ID).First(&membership)
+	assert.Equal(t, models.ProjectRoleMaintainer, membership.Role)
+
+	// Batas pemakaian habis
+	resp, response = adminRequest(router, "POST", "/project-invitations/accept", "second", map[string]string{"token": token})
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "invalid_invitation", response["code"])
+
+	// Maintainer bisa melihat dan membatalkan undangan
+	resp, response = adminRequest(router, "POST", fmt.Sprintf("/projects/%d/invitations", project.ID), "first", map[string]int{"max_uses": 0})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	invitationID := int(response["data"].(map[string]interface{})["id"].(float64))
+
+	resp, response = adminRequest(router, "GET", fmt.Sprintf("/projects/%d/invitations", project.ID), "first", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/projects/%d/invitations/%d", project.ID, invitationID), "first", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, _ = adminRequest(router, "GET", fmt.Sprintf("/projects/%d/invitations", project.ID), "second", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestProjectInvitationEmail(t *testing.T) {
+	setupProjectInvitationTestDB()
+	defer teardownProjectInvitationTestDB()
+
+	outbox := &mailer.Memory{}
+	mailer.Set(outbox)
+
+	owner := models.User{Username: "owner", Password: "hashed", Email: "owner@example.com"}
+	invitee := models.User{Username: "invitee", Password: "hashed", Email: "invitee@example.com"}
+	other := models.User{Username: "other", Password: "hashed", Email: "other@example.com"}
+	config.DB.Create(&owner)
+	config.DB.Create(&invitee)
+	config.DB.Create(&other)
+	project := seedInvitationProject(owner, invitee, other)
+	router := setupProjectInvitationRouter()
+
+	resp, _ := adminRequest(router, "POST", fmt.Sprintf("/projects/%d/invitations", project.ID), "owner",
+		map[string]interface{}{"email": "invitee@example.com", "max_uses": 5})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/projects/%d/invitations", project.ID), "owner",
+		map[string]string{"email": "owner@example.com"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp, response := adminRequest(router, "POST", fmt.Sprintf("/projects/%d/invitations", project.ID), "owner",
+		map[string]string{"email": "invitee@example.com"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Nil(t, response["url"])
+
+	messages := outbox.Messages()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	match := resetTokenPattern.FindStringSubmatch(messages[0].Body)
+	if !assert.Len(t, match, 2) {
+		return
+	}
+	token, _ := url.QueryUnescape(match[1])
+
+	resp, _ = adminRequest(router, "POST", "/project-invitations/accept", "other", map[string]string{"token": token})
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, response = adminRequest(router, "POST", "/project-invitations/accept", "invitee", map[string]string{"token": token})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "email_not_verified", response["code"])
+
+	config.DB.Model(&invitee).Update("email_verified_at", time.Now())
+	resp, _ = adminRequest(router, "POST", "/project-invitations/accept", "invitee", map[string]string{"token": token})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var membership models.ProjectUser
+	config.DB.Where("project_id = ? AND user_id = ?", project.ID, invitee.ID).First(&membership)
+	assert.Equal(t, models.ProjectRoleMember, membership.Role)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"kanban/apperror"
+	"kanban/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// bindJSON mem-parse dan memvalidasi body request ke DTO. Jika gagal, error
+// (termasuk error per field) didaftarkan ke context dan handler harus langsung return.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	validation.Setup()
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.Error(err)
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON sama seperti bindJSON, tetapi body kosong (termasuk request chunked
+// tanpa isi) dianggap valid dan req dibiarkan bernilai nol.
+func bindOptionalJSON(c *gin.Context, req interface{}) bool {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return true
+	}
+	validation.Setup()
+	if err := c.ShouldBindJSON(req); err != nil {
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		c.Error(err)
+		return false
+	}
+	return true
+}
+
+// bindQuery sama seperti bindJSON untuk query string (tag form). Nilai yang tidak
+// bisa di-parse ke tipe field-nya menjadi bad_request, bukan internal_error.
+func bindQuery(c *gin.Context, req interface{}) bool {
+	validation.Setup()
+	if err := c.ShouldBindQuery(req); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			appErr := apperror.BadRequest(apperror.CodeBadRequest)
+			appErr.Cause = err
+			err = appErr
+		}
+		c.Error(err)
+		return false
+	}
+	return true
+}
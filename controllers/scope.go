@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"kanban/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordScopeChange mencatat perubahan scope jika task berada di sprint yang sedang aktif.
+// Perubahan di sprint planned adalah bagian dari planning dan tidak dicatat.
+func recordScopeChange(tx *gorm.DB, sprintID *uint, task *models.Task, changeType string, previous, next float64) error {
+	if sprintID == nil {
+		return nil
+	}
+
+	var sprint models.Sprint
+	if err := tx.Select("id", "status").First(&sprint, *sprintID).Error; err != nil {
+		return err
+	}
+	if sprint.Status != models.SprintStatusActive {
+		return nil
+	}
+
+	change := models.NewSprintScopeChange(sprint.ID, task, changeType, previous, next, time.Now())
+	return tx.Create(&change).Error
+}
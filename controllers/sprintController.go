@@ -1,70 +1,70 @@
 package controllers
 
 import (
+	"kanban/apperror"
 	"kanban/config"
+	"kanban/metrics"
 	"kanban/models"
 	"net/http"
-
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func CreateSprint(c *gin.Context) {
-	var input struct {
-		Name      string `json:"name"`
-		ProjectID uint   `json:"project_id"`
-		Goal    string `json:"goal"`
-		EstimationType string `json:"estimation_type"`
-		TotalEstimation float64 `json:"total_estimation"`
-		RemainingEstimation float64 `json:"remaining_estimation"`
-		StartDate time.Time `json:"start_date"`
-		EndDate time.Time `json:"end_date"`
-		Status string `json:"status"`
-	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if input.Name == "" || input.ProjectID == 0 || input.EstimationType == "" || input.Status == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields"})
+	var input models.CreateSprintRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	sprint := models.Sprint{
-		Name:           input.Name,
-		ProjectID:      input.ProjectID,
-		Goal:          input.Goal,
-		EstimationType: input.EstimationType,
-		TotalEstimation: input.TotalEstimation,
+		Name:                input.Name,
+		ProjectID:           input.ProjectID,
+		Goal:                input.Goal,
+		EstimationType:      input.EstimationType,
+		TotalEstimation:     input.TotalEstimation,
 		RemainingEstimation: input.RemainingEstimation,
-		StartDate:      input.StartDate,
-		EndDate:        input.EndDate,
-		Status:        input.Status,
+		StartDate:           input.StartDate,
+		EndDate:             input.EndDate,
+		Status:              models.SprintStatusPlanned,
 	}
-	if err := config.DB.Create(&sprint).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, input.ProjectID).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+	if err := writableProject(&project); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := db(c).Create(&sprint).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": sprint})
 }
 
-
 func GetAllSprints(c *gin.Context) {
+	var query models.ArchiveQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
 	var sprints []models.Sprint
-	if err := config.DB.Preload("Tasks").Find(&sprints).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db(c).Scopes(tenantRecords(c, "sprints"), withoutArchived("sprints", query)).Preload("Tasks").Find(&sprints).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
-	
+
 	// Update estimasi untuk setiap sprint
 	for i := range sprints {
 		sprints[i].TotalEstimation = sprints[i].CalculateTotalEstimation()
 		sprints[i].RemainingEstimation = sprints[i].CalculateRemainingEstimation()
 	}
-	
+
 	c.JSON(http.StatusOK, gin.H{"data": sprints})
 }
 
@@ -72,34 +72,44 @@ func GetSprint(c *gin.Context) {
 	id := c.Param("id")
 
 	var sprint models.Sprint
-	if err := config.DB.Where("id = ?", id).Preload("Tasks").First(&sprint).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+	if err := db(c).Scopes(tenantRecords(c, "sprints")).Where("id = ?", id).Preload("Tasks").Preload("Snapshot").First(&sprint).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeSprintNotFound))
 		return
 	}
-	
+
 	// Update estimasi sprint
 	sprint.TotalEstimation = sprint.CalculateTotalEstimation()
 	sprint.RemainingEstimation = sprint.CalculateRemainingEstimation()
-	
+
 	c.JSON(http.StatusOK, gin.H{"data": sprint})
 }
 
-// GetSprintsByProject mendapatkan semua sprint dalam sebuah project
+// GetSprintsByProject mendapatkan semua sprint dalam sebuah project. Sprint yang
+// diarsipkan hanya ikut jika include_archived=true.
 func GetSprintsByProject(c *gin.Context) {
-	projectID := c.Param("project_id")
+	var query models.ArchiveQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
 
 	var sprints []models.Sprint
-	if err := config.DB.Where("project_id = ?", projectID).Preload("Tasks").Find(&sprints).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db(c).Scopes(withoutArchived("sprints", query)).Where("project_id = ?", project.ID).Preload("Tasks").Find(&sprints).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
-	
+
 	// Update estimasi untuk setiap sprint
 	for i := range sprints {
 		sprints[i].TotalEstimation = sprints[i].CalculateTotalEstimation()
 		sprints[i].RemainingEstimation = sprints[i].CalculateRemainingEstimation()
 	}
-	
+
 	c.JSON(http.StatusOK, gin.H{"data": sprints})
 }
 
@@ -108,35 +118,56 @@ func GetSprintAnalytics(c *gin.Context) {
 	id := c.Param("id")
 
 	var sprint models.Sprint
-	if err := config.DB.Where("id = ?", id).Preload("Tasks").First(&sprint).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+	if err := db(c).Scopes(tenantRecords(c, "sprints")).Where("id = ?", id).Preload("Tasks.Transitions").Preload("Snapshot").First(&sprint).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeSprintNotFound))
+		return
+	}
+
+	var scopeChanges []models.SprintScopeChange
+	if err := db(c).Where("sprint_id = ?", sprint.ID).Order("changed_at").Find(&scopeChanges).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	var carryOvers []models.TaskCarryOver
+	if err := db(c).Where("from_sprint_id = ? OR to_sprint_id = ?", sprint.ID, sprint.ID).Find(&carryOvers).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
-	
+
 	// Hitung berbagai metrik
 	totalEstimation := sprint.CalculateTotalEstimation()
 	remainingEstimation := sprint.CalculateRemainingEstimation()
 	completedEstimation := sprint.CalculateCompletedEstimation()
 	progressPercentage := sprint.GetProgressPercentage()
 	taskBreakdown := sprint.GetTaskStatusBreakdown()
-	
-	// Data untuk burndown chart (simulasi - dalam implementasi nyata bisa dari historical data)
-	burndownData := []map[string]interface{}{
-		{"day": 1, "remaining": totalEstimation},
-		{"day": 2, "remaining": totalEstimation * 0.9},
-		{"day": 3, "remaining": totalEstimation * 0.8},
-		{"day": 4, "remaining": remainingEstimation}, // Current day
-	}
-	
+
+	// Burn-up dan burndown dihitung dari riwayat status task dan perubahan scope,
+	// sehingga task yang ditambah di tengah sprint terlihat sebagai kenaikan scope
+	burnUpData := models.BuildBurnUp(&sprint, scopeChanges)
+	burndownData := make([]map[string]interface{}, 0, len(burnUpData))
+	for _, point := range burnUpData {
+		burndownData = append(burndownData, map[string]interface{}{
+			"day":       point.Day,
+			"date":      point.Date,
+			"remaining": point.Remaining,
+		})
+	}
+	if scopeChanges == nil {
+		scopeChanges = []models.SprintScopeChange{}
+	}
+
 	analytics := map[string]interface{}{
 		"sprint_info": map[string]interface{}{
-			"id":               sprint.ID,
-			"name":             sprint.Name,
-			"goal":             sprint.Goal,
-			"estimation_type":  sprint.EstimationType,
-			"start_date":       sprint.StartDate,
-			"end_date":         sprint.EndDate,
-			"status":           sprint.Status,
+			"id":              sprint.ID,
+			"name":            sprint.Name,
+			"goal":            sprint.Goal,
+			"estimation_type": sprint.EstimationType,
+			"start_date":      sprint.StartDate,
+			"end_date":        sprint.EndDate,
+			"status":          sprint.Status,
+			"started_at":      sprint.StartedAt,
+			"completed_at":    sprint.CompletedAt,
 		},
 		"estimation_summary": map[string]interface{}{
 			"total_estimation":     totalEstimation,
@@ -146,36 +177,213 @@ func GetSprintAnalytics(c *gin.Context) {
 		},
 		"task_breakdown": taskBreakdown,
 		"burndown_chart": burndownData,
-		"tasks": sprint.Tasks,
+		"burnup_chart":   burnUpData,
+		"scope_changes":  scopeChanges,
+		"scope_summary":  models.SummarizeScopeChanges(scopeChanges),
+		"tasks":          sprint.Tasks,
+		"final_snapshot": sprint.Snapshot,
+		"carry_over":     models.SummarizeCarryOvers(&sprint, carryOvers),
 	}
-	
+
 	c.JSON(http.StatusOK, gin.H{"data": analytics})
 }
 
-// UpdateSprintStatus mengupdate status sprint
+// UpdateSprintStatus mengupdate status sprint mengikuti alur planned → active → completed
 func UpdateSprintStatus(c *gin.Context) {
-	id := c.Param("id")
-	
-	var input struct {
-		Status string `json:"status" binding:"required"`
-	}
-	
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.UpdateSprintStatusRequest
+	if !bindJSON(c, &input) {
 		return
 	}
-	
+
+	switch input.Status {
+	case models.SprintStatusActive:
+		StartSprint(c)
+	case models.SprintStatusCompleted:
+		completeSprint(c, models.CompleteSprintRequest{})
+	default:
+		var sprint models.Sprint
+		if err := db(c).Scopes(tenantRecords(c, "sprints")).First(&sprint, c.Param("id")).Error; err != nil {
+			c.Error(apperror.NotFoundOr(err, apperror.CodeSprintNotFound))
+			return
+		}
+		c.Error(invalidSprintTransition(&sprint, input.Status))
+	}
+}
+
+// StartSprint memulai sprint planned. Jumlah sprint aktif per project dibatasi
+// MAX_ACTIVE_SPRINTS_PER_PROJECT (default 1, 0 berarti tidak dibatasi).
+func StartSprint(c *gin.Context) {
 	var sprint models.Sprint
-	if err := config.DB.First(&sprint, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockSprint(tx.Scopes(tenantRecords(c, "sprints")), c.Param("id"), &sprint); err != nil {
+			return err
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, &sprint.ID); err != nil {
+			return err
+		}
+		if !sprint.CanTransitionTo(models.SprintStatusActive) {
+			return invalidSprintTransition(&sprint, models.SprintStatusActive)
+		}
+
+		// Kunci baris project agar dua request start tidak lolos bersamaan
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Project{}, sprint.ProjectID).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+
+		if limit := config.GetEnvInt("MAX_ACTIVE_SPRINTS_PER_PROJECT", 1); limit > 0 {
+			var active int64
+			if err := tx.Model(&models.Sprint{}).
+				Where("project_id = ? AND status = ?", sprint.ProjectID, models.SprintStatusActive).
+				Count(&active).Error; err != nil {
+				return err
+			}
+			if active >= int64(limit) {
+				return apperror.Conflict(apperror.CodeActiveSprintLimit).
+					WithDetails(map[string]interface{}{"limit": limit})
+			}
+		}
+
+		now := time.Now()
+		sprint.Status = models.SprintStatusActive
+		sprint.StartedAt = &now
+		return tx.Select("Status", "StartedAt").Save(&sprint).Error
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	
-	sprint.Status = input.Status
-	if err := config.DB.Save(&sprint).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	c.JSON(http.StatusOK, gin.H{"data": sprint})
+}
+
+// CompleteSprint menyelesaikan sprint aktif, memindahkan task yang belum selesai
+// (ke sprint berikutnya atau backlog project) dan menyimpan snapshot metrik finalnya
+func CompleteSprint(c *gin.Context) {
+	var input models.CompleteSprintRequest
+	if !bindOptionalJSON(c, &input) {
 		return
 	}
-	
-	c.JSON(http.StatusOK, gin.H{"data": sprint})
-}
\ No newline at end of file
+	completeSprint(c, input)
+}
+
+func completeSprint(c *gin.Context, input models.CompleteSprintRequest) {
+	if input.CarryOverTo == "" {
+		input.CarryOverTo = models.CarryOverToBacklog
+	}
+	if input.CarryOverTo == models.CarryOverToSprint && input.NextSprintID == 0 {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "next_sprint_id", Code: "required"}))
+		return
+	}
+
+	var sprint models.Sprint
+	var carriedOver []models.TaskCarryOver
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockSprint(tx.Scopes(tenantRecords(c, "sprints")), c.Param("id"), &sprint); err != nil {
+			return err
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, &sprint.ID); err != nil {
+			return err
+		}
+		if !sprint.CanTransitionTo(models.SprintStatusCompleted) {
+			return invalidSprintTransition(&sprint, models.SprintStatusCompleted)
+		}
+
+		var target *uint
+		if input.CarryOverTo == models.CarryOverToSprint {
+			var next models.Sprint
+			if err := tx.Scopes(tenantRecords(c, "sprints")).First(&next, input.NextSprintID).Error; err != nil {
+				return apperror.NotFoundOr(err, apperror.CodeSprintNotFound)
+			}
+			if next.ID == sprint.ID || next.ProjectID != sprint.ProjectID || next.Status == models.SprintStatusCompleted || next.ArchivedAt != nil {
+				return apperror.Validation(apperror.FieldError{Field: "next_sprint_id", Code: "carryovertarget"})
+			}
+			target = &next.ID
+		}
+
+		if err := tx.Model(&sprint).Association("Tasks").Find(&sprint.Tasks); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		snapshot := models.NewSprintSnapshot(&sprint, now)
+
+		for _, task := range sprint.Tasks {
+			if task.Status == models.TaskStatusDone {
+				continue
+			}
+			updates := map[string]interface{}{
+				"sprint_id":        target,
+				"carry_over_count": gorm.Expr("carry_over_count + 1"),
+			}
+			// Task yang kembali ke backlog ditaruh paling bawah
+			if target == nil {
+				rank, err := nextBacklogRank(tx, sprint.ProjectID)
+				if err != nil {
+					return err
+				}
+				updates["backlog_rank"] = rank
+			}
+			if err := tx.Model(&task).Updates(updates).Error; err != nil {
+				return err
+			}
+			// Task yang dipindah ke sprint yang sudah aktif menambah scope sprint tersebut
+			if err := recordScopeChange(tx, target, &task, models.ScopeChangeAdded, 0, task.Estimation); err != nil {
+				return err
+			}
+			carriedOver = append(carriedOver, models.TaskCarryOver{
+				TaskID:       task.ID,
+				FromSprintID: sprint.ID,
+				ToSprintID:   target,
+				Estimation:   task.Estimation,
+			})
+			snapshot.CarriedOverCount++
+			snapshot.CarriedOverEstimation += task.Estimation
+		}
+		if len(carriedOver) > 0 {
+			if err := tx.Create(&carriedOver).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&snapshot).Error; err != nil {
+			return err
+		}
+
+		sprint.Status = models.SprintStatusCompleted
+		sprint.CompletedAt = &now
+		sprint.TotalEstimation = snapshot.TotalEstimation
+		sprint.RemainingEstimation = snapshot.RemainingEstimation
+		sprint.Snapshot = &snapshot
+		return tx.Select("Status", "CompletedAt", "TotalEstimation", "RemainingEstimation").Save(&sprint).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	metrics.SprintsCompletedTotal.Inc()
+
+	if carriedOver == nil {
+		carriedOver = []models.TaskCarryOver{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sprint, "carried_over": carriedOver})
+}
+
+// lockSprint mengambil sprint dengan row lock untuk perubahan status
+func lockSprint(tx *gorm.DB, id interface{}, sprint *models.Sprint) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(sprint, id).Error; err != nil {
+		return apperror.NotFoundOr(err, apperror.CodeSprintNotFound)
+	}
+	return nil
+}
+
+func invalidSprintTransition(sprint *models.Sprint, to string) *apperror.Error {
+	allowed := sprint.AllowedTransitions()
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return apperror.Conflict(apperror.CodeInvalidSprintTransition).WithDetails(map[string]interface{}{
+		"from":    sprint.Status,
+		"to":      to,
+		"allowed": allowed,
+	})
+}
@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"kanban/config"
+	"kanban/middlewares"
 	"kanban/models"
 
 	"github.com/gin-gonic/gin"
@@ -43,7 +46,8 @@ func setupSprintTestDB() {
 		log.Fatal("Failed to connect test database:", err)
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{})
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.SprintSnapshot{}, &models.Task{}, &models.TaskCarryOver{}, &models.TaskStatusTransition{}, &models.SprintScopeChange{},
+		&models.Organization{}, &models.OrganizationMember{})
 
 	config.DB = db
 }
@@ -52,9 +56,15 @@ func teardownSprintTestDB() {
 	if config.DB != nil {
 		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
 		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE task_carry_overs")
+		config.DB.Exec("TRUNCATE TABLE task_status_transitions")
+		config.DB.Exec("TRUNCATE TABLE sprint_scope_changes")
+		config.DB.Exec("TRUNCATE TABLE sprint_snapshots")
 		config.DB.Exec("TRUNCATE TABLE sprints")
 		config.DB.Exec("TRUNCATE TABLE projects")
 		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
 		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
 
 		sqlDB, _ := config.DB.DB()
@@ -68,13 +78,13 @@ func TestCreateSprintWithHourEstimation(t *testing.T) {
 	setupSprintTestDB()
 	defer teardownSprintTestDB()
 
-	project := models.Project{
-		Name: "Test Project",
-	}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/sprints", CreateSprint)
 
 	sprintData := map[string]interface{}{
@@ -109,13 +119,13 @@ func TestCreateSprintWithStoryPointEstimation(t *testing.T) {
 	setupSprintTestDB()
 	defer teardownSprintTestDB()
 
-	project := models.Project{
-		Name: "Test Project",
-	}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/sprints", CreateSprint)
 
 	sprintData := map[string]interface{}{
@@ -150,7 +160,8 @@ func TestGetSprintWithEstimationCalculation(t *testing.T) {
 	setupSprintTestDB()
 	defer teardownSprintTestDB()
 
-	project := models.Project{Name: "Test Project"}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	sprint := models.Sprint{
@@ -167,19 +178,19 @@ func TestGetSprintWithEstimationCalculation(t *testing.T) {
 	task1 := models.Task{
 		Title:      "Task 1",
 		Status:     "todo",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 5.0,
 	}
 	task2 := models.Task{
 		Title:      "Task 2",
 		Status:     "done",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 3.0,
 	}
 	task3 := models.Task{
 		Title:      "Task 3",
 		Status:     "in_progress",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 2.0,
 	}
 	config.DB.Create(&task1)
@@ -188,6 +199,7 @@ func TestGetSprintWithEstimationCalculation(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/sprints/:id", GetSprint)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/sprints/%d", sprint.ID), nil)
@@ -208,7 +220,8 @@ func TestGetSprintAnalytics(t *testing.T) {
 	setupSprintTestDB()
 	defer teardownSprintTestDB()
 
-	project := models.Project{Name: "Test Project"}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	sprint := models.Sprint{
@@ -222,10 +235,10 @@ func TestGetSprintAnalytics(t *testing.T) {
 	}
 	config.DB.Create(&sprint)
 
-	task1 := models.Task{Title: "Task 1", Status: "todo", SprintID: sprint.ID, Estimation: 8.0}
-	task2 := models.Task{Title: "Task 2", Status: "done", SprintID: sprint.ID, Estimation: 5.0}
-	task3 := models.Task{Title: "Task 3", Status: "in_progress", SprintID: sprint.ID, Estimation: 3.0}
-	task4 := models.Task{Title: "Task 4", Status: "done", SprintID: sprint.ID, Estimation: 2.0}
+	task1 := models.Task{Title: "Task 1", Status: "todo", SprintID: &sprint.ID, Estimation: 8.0}
+	task2 := models.Task{Title: "Task 2", Status: "done", SprintID: &sprint.ID, Estimation: 5.0}
+	task3 := models.Task{Title: "Task 3", Status: "in_progress", SprintID: &sprint.ID, Estimation: 3.0}
+	task4 := models.Task{Title: "Task 4", Status: "done", SprintID: &sprint.ID, Estimation: 2.0}
 	config.DB.Create(&task1)
 	config.DB.Create(&task2)
 	config.DB.Create(&task3)
@@ -233,6 +246,7 @@ func TestGetSprintAnalytics(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/sprints/:id/analytics", GetSprintAnalytics)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/sprints/%d/analytics", sprint.ID), nil)
@@ -267,7 +281,8 @@ func TestUpdateSprintStatus(t *testing.T) {
 	setupSprintTestDB()
 	defer teardownSprintTestDB()
 
-	project := models.Project{Name: "Test Project"}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	sprint := models.Sprint{
@@ -283,6 +298,7 @@ func TestUpdateSprintStatus(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.PUT("/sprints/:id/status", UpdateSprintStatus)
 
 	updateData := map[string]string{
@@ -307,8 +323,9 @@ func TestGetSprintsByProject(t *testing.T) {
 	setupSprintTestDB()
 	defer teardownSprintTestDB()
 
-	project1 := models.Project{Name: "Project 1"}
-	project2 := models.Project{Name: "Project 2"}
+	org := seedTenant()
+	project1 := models.Project{Name: "Project 1", OrganizationID: &org.ID}
+	project2 := models.Project{Name: "Project 2", OrganizationID: &org.ID}
 	config.DB.Create(&project1)
 	config.DB.Create(&project2)
 
@@ -321,7 +338,8 @@ func TestGetSprintsByProject(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.GET("/projects/:project_id/sprints", GetSprintsByProject)
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/projects/:id/sprints", GetSprintsByProject)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/sprints", project1.ID), nil)
 	resp := httptest.NewRecorder()
@@ -340,6 +358,7 @@ func TestCreateSprintMissingRequiredFields(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/sprints", CreateSprint)
 
 	sprintData := map[string]interface{}{
@@ -355,9 +374,110 @@ func TestCreateSprintMissingRequiredFields(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 
-	var response map[string]string
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "validation_failed", response["code"])
+}
+
+func TestCreateSprintEndDateBeforeStartDate(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints", CreateSprint)
+
+	sprintData := map[string]interface{}{
+		"name":            "Backwards Sprint",
+		"project_id":      project.ID,
+		"estimation_type": "weeks",
+		"start_date":      time.Now().Format(time.RFC3339),
+		"end_date":        time.Now().AddDate(0, 0, -1).Format(time.RFC3339),
+		"status":          "planned",
+	}
+
+	jsonData, _ := json.Marshal(sprintData)
+	req, _ := http.NewRequest("POST", "/sprints", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response struct {
+		Code   string `json:"code"`
+		Errors []struct {
+			Field string `json:"field"`
+			Code  string `json:"code"`
+		} `json:"errors"`
+	}
 	json.Unmarshal(resp.Body.Bytes(), &response)
-	assert.Contains(t, response["error"], "Missing required fields")
+	assert.Equal(t, "validation_failed", response.Code)
+
+	fieldCodes := map[string]string{}
+	for _, fe := range response.Errors {
+		fieldCodes[fe.Field] = fe.Code
+	}
+	assert.Equal(t, "gtfield", fieldCodes["end_date"])
+	assert.Equal(t, "estimationtype", fieldCodes["estimation_type"])
+
+	var count int64
+	config.DB.Model(&models.Sprint{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCreateSprintTooLong(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints", CreateSprint)
+
+	start := time.Now()
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"name":            "Endless Sprint",
+		"project_id":      project.ID,
+		"estimation_type": "hour",
+		"start_date":      start.Format(time.RFC3339),
+		"end_date":        time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC).Format(time.RFC3339),
+	})
+	req, _ := http.NewRequest("POST", "/sprints", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response struct {
+		Errors []struct {
+			Field string `json:"field"`
+			Code  string `json:"code"`
+		} `json:"errors"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "end_date", response.Errors[0].Field)
+		assert.Equal(t, "maxrange", response.Errors[0].Code)
+	}
+
+	// Sprint lama dengan tanggal tidak wajar tetap dibatasi saat membuat chart harian
+	farFuture := start.AddDate(5000, 0, 0)
+	sprint := models.Sprint{StartDate: start, EndDate: farFuture, CompletedAt: &farFuture}
+	from, to := sprint.ReportingPeriod()
+	assert.Equal(t, start.AddDate(0, 0, models.MaxSprintDays), to)
+	assert.Equal(t, start, from)
 }
 
 func TestGetSprintNotFound(t *testing.T) {
@@ -366,6 +486,7 @@ func TestGetSprintNotFound(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.GET("/sprints/:id", GetSprint)
 
 	req, _ := http.NewRequest("GET", "/sprints/99999", nil)
@@ -374,3 +495,391 @@ func TestGetSprintNotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, resp.Code)
 }
+
+func TestStartSprint(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "planned",
+	}
+	config.DB.Create(&sprint)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/start", StartSprint)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/start", sprint.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updatedSprint models.Sprint
+	config.DB.First(&updatedSprint, sprint.ID)
+	assert.Equal(t, "active", updatedSprint.Status)
+	assert.NotNil(t, updatedSprint.StartedAt)
+}
+
+func TestStartSprintWhenAnotherSprintIsActive(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	active := models.Sprint{
+		Name:           "Active Sprint",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	planned := models.Sprint{
+		Name:           "Next Sprint",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now().AddDate(0, 0, 7),
+		EndDate:        time.Now().AddDate(0, 0, 14),
+		Status:         "planned",
+	}
+	config.DB.Create(&active)
+	config.DB.Create(&planned)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/start", StartSprint)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/start", planned.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "active_sprint_limit_reached", response["code"])
+
+	var unchanged models.Sprint
+	config.DB.First(&unchanged, planned.ID)
+	assert.Equal(t, "planned", unchanged.Status)
+}
+
+func TestCompleteSprintCreatesSnapshot(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "story_point",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	config.DB.Create(&sprint)
+	config.DB.Create(&models.Task{Title: "Done", Status: "done", SprintID: &sprint.ID, Estimation: 5})
+	config.DB.Create(&models.Task{Title: "Todo", Status: "todo", SprintID: &sprint.ID, Estimation: 3})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/complete", CompleteSprint)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/complete", sprint.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updatedSprint models.Sprint
+	config.DB.Preload("Snapshot").First(&updatedSprint, sprint.ID)
+	assert.Equal(t, "completed", updatedSprint.Status)
+	assert.NotNil(t, updatedSprint.CompletedAt)
+	if assert.NotNil(t, updatedSprint.Snapshot) {
+		assert.Equal(t, 8.0, updatedSprint.Snapshot.TotalEstimation)
+		assert.Equal(t, 5.0, updatedSprint.Snapshot.CompletedEstimation)
+		assert.Equal(t, 1, updatedSprint.Snapshot.DoneCount)
+		assert.Equal(t, 1, updatedSprint.Snapshot.TodoCount)
+	}
+}
+
+func TestCompleteSprintWithEmptyChunkedBody(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	config.DB.Create(&sprint)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/complete", CompleteSprint)
+
+	// Body chunked tanpa isi tidak punya Content-Length (-1)
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/complete", sprint.ID), io.NopCloser(strings.NewReader("")))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updatedSprint models.Sprint
+	config.DB.First(&updatedSprint, sprint.ID)
+	assert.Equal(t, "completed", updatedSprint.Status)
+}
+
+func TestUpdateSprintStatusRejectsInvalidTransition(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Finished Sprint",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "completed",
+	}
+	config.DB.Create(&sprint)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.PUT("/sprints/:id/status", UpdateSprintStatus)
+
+	jsonData, _ := json.Marshal(map[string]string{"status": "planned"})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/sprints/%d/status", sprint.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "invalid_sprint_transition", response["code"])
+
+	var unchanged models.Sprint
+	config.DB.First(&unchanged, sprint.ID)
+	assert.Equal(t, "completed", unchanged.Status)
+}
+
+func TestCompleteSprintCarriesOverToNextSprint(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	config.DB.Create(&sprint)
+	next := models.Sprint{
+		Name:           "Sprint 2",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now().AddDate(0, 0, 7),
+		EndDate:        time.Now().AddDate(0, 0, 14),
+		Status:         "planned",
+	}
+	config.DB.Create(&next)
+
+	done := models.Task{Title: "Done", Status: "done", SprintID: &sprint.ID, Estimation: 5}
+	unfinished := models.Task{Title: "In Progress", Status: "in_progress", SprintID: &sprint.ID, Estimation: 3}
+	config.DB.Create(&done)
+	config.DB.Create(&unfinished)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/complete", CompleteSprint)
+	router.GET("/sprints/:id/analytics", GetSprintAnalytics)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"carry_over_to": "sprint", "next_sprint_id": next.ID})
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/complete", sprint.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var moved models.Task
+	config.DB.First(&moved, unfinished.ID)
+	if assert.NotNil(t, moved.SprintID) {
+		assert.Equal(t, next.ID, *moved.SprintID)
+	}
+	assert.Equal(t, 1, moved.CarryOverCount)
+
+	var stayed models.Task
+	config.DB.First(&stayed, done.ID)
+	assert.Equal(t, sprint.ID, *stayed.SprintID)
+
+	var snapshot models.SprintSnapshot
+	config.DB.Where("sprint_id = ?", sprint.ID).First(&snapshot)
+	assert.Equal(t, 1, snapshot.CarriedOverCount)
+	assert.Equal(t, 3.0, snapshot.CarriedOverEstimation)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/sprints/%d/analytics", next.ID), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	carryOver := response["data"].(map[string]interface{})["carry_over"].(map[string]interface{})
+	assert.Equal(t, 1.0, carryOver["carried_in_count"])
+	assert.Equal(t, 3.0, carryOver["carried_in_estimation"])
+}
+
+func TestCompleteSprintCarriesOverToBacklog(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	config.DB.Create(&sprint)
+	unfinished := models.Task{Title: "Todo", Status: "todo", SprintID: &sprint.ID, Estimation: 2}
+	config.DB.Create(&unfinished)
+	config.DB.Create(&models.Task{Title: "Backlog", Status: "todo", ProjectID: project.ID, BacklogRank: 3})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/sprints/:id/complete", CompleteSprint)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/sprints/%d/complete", sprint.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var moved models.Task
+	config.DB.First(&moved, unfinished.ID)
+	assert.Nil(t, moved.SprintID)
+	assert.Equal(t, project.ID, moved.ProjectID)
+	assert.Equal(t, 1, moved.CarryOverCount)
+	assert.Equal(t, 4, moved.BacklogRank)
+
+	var record models.TaskCarryOver
+	assert.NoError(t, config.DB.Where("task_id = ?", unfinished.ID).First(&record).Error)
+	assert.Equal(t, sprint.ID, record.FromSprintID)
+	assert.Nil(t, record.ToSprintID)
+}
+
+func TestGetSprintAnalyticsBurnUpWithScopeChange(t *testing.T) {
+	setupSprintTestDB()
+	defer teardownSprintTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	startedAt := time.Now().AddDate(0, 0, -2)
+	sprint := models.Sprint{
+		Name:           "Sprint 1",
+		ProjectID:      project.ID,
+		EstimationType: "hour",
+		StartDate:      startedAt,
+		EndDate:        time.Now().AddDate(0, 0, 5),
+		Status:         "active",
+		StartedAt:      &startedAt,
+	}
+	config.DB.Create(&sprint)
+
+	original := models.Task{Title: "Original", Status: "todo", SprintID: &sprint.ID, Estimation: 5}
+	original.CreatedAt = startedAt
+	config.DB.Create(&original)
+
+	// Task ditambahkan hari ini setelah sprint aktif
+	added := models.Task{Title: "Added", Status: "todo", SprintID: &sprint.ID, Estimation: 3}
+	config.DB.Create(&added)
+	config.DB.Create(&models.SprintScopeChange{
+		SprintID:      sprint.ID,
+		TaskID:        added.ID,
+		TaskTitle:     added.Title,
+		Type:          "added",
+		NewEstimation: 3,
+		Delta:         3,
+		ChangedAt:     added.CreatedAt,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/sprints/:id/analytics", GetSprintAnalytics)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/sprints/%d/analytics", sprint.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data struct {
+			BurnUp       []models.BurnUpPoint       `json:"burnup_chart"`
+			ScopeChanges []models.SprintScopeChange `json:"scope_changes"`
+			ScopeSummary models.ScopeChangeSummary  `json:"scope_summary"`
+		} `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+
+	if assert.Len(t, response.Data.BurnUp, 3) {
+		assert.Equal(t, 5.0, response.Data.BurnUp[0].TotalScope)
+		assert.Equal(t, 8.0, response.Data.BurnUp[2].TotalScope)
+	}
+	assert.Len(t, response.Data.ScopeChanges, 1)
+	assert.Equal(t, 1, response.Data.ScopeSummary.AddedCount)
+	assert.Equal(t, 3.0, response.Data.ScopeSummary.NetChange)
+}
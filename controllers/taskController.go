@@ -1,35 +1,30 @@
 package controllers
 
 import (
-	"kanban/config"
+	"kanban/apperror"
+	"kanban/metrics"
 	"kanban/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func CreateTask(c *gin.Context) {
-	var input struct {
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		SprintID  uint   `json:"sprint_id"`
-		AssignTo  uint   `json:"assign_to"`
-		Estimation float64 `json:"estimation"`		
-	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.CreateTaskRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	if input.Title == "" || input.Status == "" || input.SprintID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields"})
+	if input.ProjectID == 0 && input.SprintID == 0 {
+		c.Error(apperror.Validation(apperror.FieldError{Field: "project_id", Code: "required"}))
 		return
 	}
 
 	task := models.Task{
 		Title:      input.Title,
 		Status:     input.Status,
-		SprintID:   input.SprintID,
+		ProjectID:  input.ProjectID,
 		Estimation: input.Estimation,
 	}
 
@@ -37,18 +32,68 @@ func CreateTask(c *gin.Context) {
 		task.AssignTo = &input.AssignTo
 	}
 
-	if err := config.DB.Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if input.SprintID != 0 {
+			var sprint models.Sprint
+			if err := tx.Scopes(tenantRecords(c, "sprints")).First(&sprint, input.SprintID).Error; err != nil {
+				return apperror.NotFoundOr(err, apperror.CodeSprintNotFound)
+			}
+			if task.ProjectID != 0 && task.ProjectID != sprint.ProjectID {
+				return apperror.Validation(apperror.FieldError{Field: "sprint_id", Code: "sprintproject"})
+			}
+			task.ProjectID = sprint.ProjectID
+			task.SprintID = &sprint.ID
+		}
+
+		var project models.Project
+		if err := tx.Scopes(tenantProjects(c)).First(&project, task.ProjectID).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+		if err := ensureWritable(tx, project.ID, task.SprintID); err != nil {
+			return err
+		}
+		if task.AssignTo != nil {
+			if err := requireOrgMembers(tx, project.OrganizationID, "assign_to", *task.AssignTo); err != nil {
+				return err
+			}
+		}
+
+		if task.SprintID == nil {
+			// Tanpa sprint, task masuk ke urutan terbawah backlog project
+			rank, err := nextBacklogRank(tx, task.ProjectID)
+			if err != nil {
+				return err
+			}
+			task.BacklogRank = rank
+		}
+		return createTask(tx, &task)
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	metrics.TasksCreatedTotal.Inc()
 
 	c.JSON(http.StatusOK, gin.H{"data": task})
 }
 
+// createTask menyimpan task baru beserta transisi status awalnya, dan mencatat
+// perubahan scope jika task langsung masuk ke sprint yang sedang aktif
+func createTask(tx *gorm.DB, task *models.Task) error {
+	if err := tx.Create(task).Error; err != nil {
+		return err
+	}
+	transition := models.NewTaskStatusTransition(task, "", task.CreatedAt)
+	if err := tx.Create(&transition).Error; err != nil {
+		return err
+	}
+	return recordScopeChange(tx, task.SprintID, task, models.ScopeChangeAdded, 0, task.Estimation)
+}
+
 func GetAllTasks(c *gin.Context) {
 	var tasks []models.Task
-	if err := config.DB.Preload("Sprint").Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db(c).Scopes(tenantRecords(c, "tasks")).Preload("Sprint").Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": tasks})
@@ -58,8 +103,8 @@ func GetTasks(c *gin.Context) {
 	id := c.Param("id")
 
 	var tasks []models.Task
-	if err := config.DB.Where("sprint_id = ?", id).Preload("Sprint").Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db(c).Scopes(tenantRecords(c, "tasks")).Where("sprint_id = ?", id).Preload("Sprint").Find(&tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": tasks})
@@ -69,42 +114,113 @@ func UpdateTaskStatus(c *gin.Context) {
 	id := c.Param("id")
 	var task models.Task
 
-	if err := config.DB.First(&task, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task tidak ditemukan"})
+	if err := db(c).Scopes(tenantRecords(c, "tasks")).First(&task, id).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeTaskNotFound))
+		return
+	}
+	if err := ensureWritable(db(c), task.ProjectID, task.SprintID); err != nil {
+		c.Error(err)
 		return
 	}
 
-	var body struct {
-		Status string `json:"status"`
+	var body models.UpdateTaskStatusRequest
+	if !bindJSON(c, &body) {
+		return
 	}
-	c.BindJSON(&body)
 
+	previousStatus := task.Status
 	task.Status = body.Status
-	config.DB.Save(&task)
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		if previousStatus == task.Status {
+			return nil
+		}
+		transition := models.NewTaskStatusTransition(&task, previousStatus, task.UpdatedAt)
+		return tx.Create(&transition).Error
+	})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if previousStatus != task.Status {
+		metrics.TaskStatusTransitionsTotal.WithLabelValues(previousStatus, task.Status).Inc()
+	}
 
 	c.JSON(http.StatusOK, task)
 }
 
+// UpdateTaskEstimation mengubah estimasi task; perubahan di sprint aktif dicatat sebagai scope change
+func UpdateTaskEstimation(c *gin.Context) {
+	var body models.UpdateTaskEstimationRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	var task models.Task
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(tenantRecords(c, "tasks")).First(&task, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeTaskNotFound)
+		}
+		if err := ensureWritable(tx, task.ProjectID, task.SprintID); err != nil {
+			return err
+		}
+		previous := task.Estimation
+		if previous == body.Estimation {
+			return nil
+		}
+
+		task.Estimation = body.Estimation
+		if err := tx.Model(&task).Update("estimation", task.Estimation).Error; err != nil {
+			return err
+		}
+		return recordScopeChange(tx, task.SprintID, &task, models.ScopeChangeReEstimated, previous, task.Estimation)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": task})
+}
+
 func AssignToUser(c *gin.Context) {
 	id := c.Param("id")
 	var task models.Task
-	if err := config.DB.First(&task, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	if err := db(c).Scopes(tenantRecords(c, "tasks")).First(&task, id).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeTaskNotFound))
 		return
 	}
-	var body struct {
-		AssignTo uint `json:"assign_to"`
+	if err := ensureWritable(db(c), task.ProjectID, task.SprintID); err != nil {
+		c.Error(err)
+		return
+	}
+	var body models.AssignTaskRequest
+	if !bindJSON(c, &body) {
+		return
 	}
-	c.BindJSON(&body)
-	
+
 	// If AssignTo is 0, set to nil (unassign)
 	if body.AssignTo == 0 {
 		task.AssignTo = nil
 	} else {
+		var project models.Project
+		if err := db(c).Select("id", "organization_id").First(&project, task.ProjectID).Error; err != nil {
+			c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+			return
+		}
+		if err := requireOrgMembers(db(c), project.OrganizationID, "assign_to", body.AssignTo); err != nil {
+			c.Error(err)
+			return
+		}
 		task.AssignTo = &body.AssignTo
 	}
-	
-	config.DB.Save(&task)
+
+	if err := db(c).Save(&task).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
 
 	c.JSON(http.StatusOK, task)
 }
@@ -112,10 +228,23 @@ func AssignToUser(c *gin.Context) {
 func DeleteTask(c *gin.Context) {
 	id := c.Param("id")
 	var task models.Task
-	if err := config.DB.First(&task, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	if err := db(c).Scopes(tenantRecords(c, "tasks")).First(&task, id).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeTaskNotFound))
+		return
+	}
+	if err := ensureWritable(db(c), task.ProjectID, task.SprintID); err != nil {
+		c.Error(err)
+		return
+	}
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		return recordScopeChange(tx, task.SprintID, &task, models.ScopeChangeRemoved, task.Estimation, 0)
+	})
+	if err != nil {
+		c.Error(apperror.Internal(err))
 		return
 	}
-	config.DB.Delete(&task)
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
-}
\ No newline at end of file
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"kanban/config"
+	"kanban/middlewares"
 	"kanban/models"
 
 	"github.com/gin-gonic/gin"
@@ -44,7 +45,8 @@ func setupTaskTestDB() {
 		log.Fatal("Failed to connect test database:", err)
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Task{})
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.Sprint{}, &models.Task{}, &models.TaskStatusTransition{}, &models.SprintScopeChange{},
+		&models.Organization{}, &models.OrganizationMember{})
 
 	config.DB = db
 }
@@ -52,10 +54,14 @@ func setupTaskTestDB() {
 func teardownTaskTestDB() {
 	if config.DB != nil {
 		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE task_status_transitions")
+		config.DB.Exec("TRUNCATE TABLE sprint_scope_changes")
 		config.DB.Exec("TRUNCATE TABLE tasks")
 		config.DB.Exec("TRUNCATE TABLE project_users")
 		config.DB.Exec("TRUNCATE TABLE projects")
 		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
 		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
 
 		sqlDB, _ := config.DB.DB()
@@ -69,10 +75,8 @@ func TestCreateTask(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project := models.Project{
-		Name:        "Test Project",
-		Description: "Test",
-	}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", Description: "Test", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
@@ -81,9 +85,11 @@ func TestCreateTask(t *testing.T) {
 		Password: string(hashedPassword),
 	}
 	config.DB.Create(&user)
+	joinTenant(org.ID, user)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/tasks", CreateTask)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -116,7 +122,8 @@ func TestCreateTask(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Test Task", task.Title)
 	assert.Equal(t, "todo", task.Status)
-	assert.Equal(t, sprint.ID, task.SprintID)
+	assert.Equal(t, sprint.ID, *task.SprintID)
+	assert.Equal(t, project.ID, task.ProjectID)
 	assert.Equal(t, user.ID, *task.AssignTo)
 }
 
@@ -124,9 +131,8 @@ func TestCreateTaskWithoutAssignee(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project := models.Project{
-		Name: "Test Project",
-	}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -139,6 +145,7 @@ func TestCreateTaskWithoutAssignee(t *testing.T) {
 	// fmt.Println("Created sprint with ID:", sprint.ID)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.POST("/tasks", CreateTask)
 
 	taskData := map[string]interface{}{
@@ -168,6 +175,7 @@ func TestCreateTaskInvalidJSON(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/tasks", CreateTask)
 
 	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString("invalid json"))
@@ -185,6 +193,7 @@ func TestCreateTaskMissingRequiredFields(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.POST("/tasks", CreateTask)
 
 	taskData := map[string]interface{}{
@@ -201,16 +210,67 @@ func TestCreateTaskMissingRequiredFields(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 
-	var response map[string]string
+	var response map[string]interface{}
 	json.Unmarshal(resp.Body.Bytes(), &response)
-	assert.Contains(t, response["error"], "Missing required fields")
+	assert.Equal(t, "validation_failed", response["code"])
+}
+
+func TestCreateTaskInvalidReferences(t *testing.T) {
+	setupTaskTestDB()
+	defer teardownTaskTestDB()
+
+	// Sprint milik tenant lain diperlakukan sama dengan sprint yang tidak ada
+	other := seedTenant()
+	foreignProject := models.Project{Name: "Foreign Project", OrganizationID: &other.ID}
+	config.DB.Create(&foreignProject)
+	foreignSprint := models.Sprint{ProjectID: foreignProject.ID, Name: "Foreign Sprint", EstimationType: "hour", StartDate: time.Now(), EndDate: time.Now().AddDate(0, 0, 14)}
+	config.DB.Create(&foreignSprint)
+	org := seedTenant()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/tasks", CreateTask)
+
+	post := func(body map[string]interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var response map[string]interface{}
+		json.Unmarshal(resp.Body.Bytes(), &response)
+		return resp, response
+	}
+
+	resp, response := post(map[string]interface{}{"title": "Bad Task", "status": "todo", "sprint_id": 99999, "estimation": -2})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	fieldCodes := map[string]string{}
+	for _, fe := range response["errors"].([]interface{}) {
+		fieldError := fe.(map[string]interface{})
+		fieldCodes[fieldError["field"].(string)] = fieldError["code"].(string)
+	}
+	assert.Equal(t, "gte", fieldCodes["estimation"])
+	assert.NotContains(t, fieldCodes, "sprint_id")
+
+	for _, sprintID := range []uint{99999, foreignSprint.ID} {
+		resp, response = post(map[string]interface{}{"title": "Bad Task", "status": "todo", "sprint_id": sprintID})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, "sprint_not_found", response["code"])
+	}
+
+	var count int64
+	config.DB.Model(&models.Task{}).Count(&count)
+	assert.Equal(t, int64(0), count)
 }
 
 func TestGetAllTasks(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project := models.Project{Name: "Test Project"}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 
 	sprint := models.Sprint{
@@ -225,13 +285,13 @@ func TestGetAllTasks(t *testing.T) {
 	task1 := models.Task{
 		Title:      "Task 1",
 		Status:     "todo",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 2.0,
 	}
 	task2 := models.Task{
 		Title:      "Task 2",
 		Status:     "in_progress",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 4.0,
 	}
 	config.DB.Create(&task1)
@@ -239,6 +299,7 @@ func TestGetAllTasks(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/tasks", GetAllTasks)
 
 	req, _ := http.NewRequest("GET", "/tasks", nil)
@@ -258,6 +319,7 @@ func TestGetAllTasksEmpty(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.GET("/tasks", GetAllTasks)
 
 	req, _ := http.NewRequest("GET", "/tasks", nil)
@@ -275,8 +337,9 @@ func TestGetTasks(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project1 := models.Project{Name: "Project 1"}
-	project2 := models.Project{Name: "Project 2"}
+	org := seedTenant()
+	project1 := models.Project{Name: "Project 1", OrganizationID: &org.ID}
+	project2 := models.Project{Name: "Project 2", OrganizationID: &org.ID}
 	config.DB.Create(&project1)
 	config.DB.Create(&project2)
 
@@ -296,15 +359,16 @@ func TestGetTasks(t *testing.T) {
 	}
 	config.DB.Create(&sprint1)
 	config.DB.Create(&sprint2)
-	task1 := models.Task{Title: "Task 1", Status: "todo",  SprintID: sprint1.ID, Estimation: 3.0}
-	task2 := models.Task{Title: "Task 2", Status: "todo", SprintID: sprint1.ID, Estimation: 5.0}
-	task3 := models.Task{Title: "Task 3", Status: "todo", SprintID: sprint2.ID, Estimation: 2.0}
+	task1 := models.Task{Title: "Task 1", Status: "todo",  SprintID: &sprint1.ID, Estimation: 3.0}
+	task2 := models.Task{Title: "Task 2", Status: "todo", SprintID: &sprint1.ID, Estimation: 5.0}
+	task3 := models.Task{Title: "Task 3", Status: "todo", SprintID: &sprint2.ID, Estimation: 2.0}
 	config.DB.Create(&task1)
 	config.DB.Create(&task2)
 	config.DB.Create(&task3)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.GET("/projects/:id/tasks", GetTasks)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/tasks", sprint1.ID), nil)
@@ -322,7 +386,8 @@ func TestUpdateTaskStatus(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project := models.Project{Name: "Test Project"}
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -335,13 +400,14 @@ func TestUpdateTaskStatus(t *testing.T) {
 	task := models.Task{
 		Title:      "Test Task",
 		Status:     "todo",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 5.0,
 	}
 	config.DB.Create(&task)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.PUT("/tasks/:id", UpdateTaskStatus)
 
 	updateData := map[string]string{
@@ -360,6 +426,11 @@ func TestUpdateTaskStatus(t *testing.T) {
 	var updatedTask models.Task
 	config.DB.First(&updatedTask, task.ID)
 	assert.Equal(t, "in_progress", updatedTask.Status)
+
+	var transition models.TaskStatusTransition
+	assert.NoError(t, config.DB.Where("task_id = ?", task.ID).Last(&transition).Error)
+	assert.Equal(t, "todo", transition.FromStatus)
+	assert.Equal(t, "in_progress", transition.ToStatus)
 }
 
 func TestUpdateTaskStatusNotFound(t *testing.T) {
@@ -368,6 +439,7 @@ func TestUpdateTaskStatusNotFound(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.PUT("/tasks/:id", UpdateTaskStatus)
 
 	updateData := map[string]string{
@@ -384,11 +456,42 @@ func TestUpdateTaskStatusNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, resp.Code)
 }
 
+func TestUpdateTaskStatusNotFoundProblemJSON(t *testing.T) {
+	setupTaskTestDB()
+	defer teardownTaskTestDB()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.RequestID(), middlewares.ErrorHandler())
+	router.PUT("/tasks/:id", UpdateTaskStatus)
+
+	jsonData, _ := json.Marshal(map[string]string{"status": "done"})
+	req, _ := http.NewRequest("PUT", "/tasks/99999", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+	req.Header.Set("X-Request-ID", "req-123")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/problem+json")
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, "task_not_found", response["code"])
+	assert.Equal(t, "Task tidak ditemukan", response["detail"])
+	assert.Equal(t, float64(http.StatusNotFound), response["status"])
+	assert.Equal(t, "/tasks/99999", response["instance"])
+	assert.Equal(t, "req-123", response["request_id"])
+}
+
 func TestUpdateTaskStatusInvalidJSON(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project := models.Project{Name: "Test"}
+	org := seedTenant()
+	project := models.Project{Name: "Test", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -398,11 +501,12 @@ func TestUpdateTaskStatusInvalidJSON(t *testing.T) {
 		EndDate:        time.Now().AddDate(0, 0, 7),
 	}
 	config.DB.Create(&sprint)
-	task := models.Task{Title: "Test", Status: "todo", SprintID: sprint.ID, Estimation: 5.0}
+	task := models.Task{Title: "Test", Status: "todo", SprintID: &sprint.ID, Estimation: 5.0}
 	config.DB.Create(&task)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.PUT("/tasks/:id", UpdateTaskStatus)
 
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), bytes.NewBufferString("invalid json"))
@@ -425,7 +529,8 @@ func TestAssignToUser(t *testing.T) {
 	}
 	config.DB.Create(&user)
 
-	project := models.Project{Name: "Test Project"}
+	org := seedTenant(user)
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -438,13 +543,14 @@ func TestAssignToUser(t *testing.T) {
 	task := models.Task{
 		Title:      "Unassigned Task",
 		Status:     "todo",
-		SprintID:   sprint.ID,
+		SprintID:   &sprint.ID,
 		Estimation: 5.0,
 	}
 	config.DB.Create(&task)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.PUT("/tasks/:id/assign", AssignToUser)
 
 	assignData := map[string]uint{
@@ -471,6 +577,7 @@ func TestAssignToUserNotFound(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
 	router.PUT("/tasks/:id/assign", AssignToUser)
 
 	assignData := map[string]uint{
@@ -498,7 +605,8 @@ func TestAssignToUserUnassign(t *testing.T) {
 	}
 	config.DB.Create(&user)
 
-	project := models.Project{Name: "Test"}
+	org := seedTenant()
+	project := models.Project{Name: "Test", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -511,13 +619,14 @@ func TestAssignToUserUnassign(t *testing.T) {
 	task := models.Task{
 		Title:     "Assigned Task",
 		Status:    "todo",
-		SprintID:  sprint.ID,
+		SprintID:  &sprint.ID,
 		AssignTo:  &user.ID,
 	}
 	config.DB.Create(&task)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.PUT("/tasks/:id/assign", AssignToUser)
 
 
@@ -543,7 +652,8 @@ func TestDeleteTask(t *testing.T) {
 	setupTaskTestDB()
 	defer teardownTaskTestDB()
 
-	project := models.Project{Name: "Test"}
+	org := seedTenant()
+	project := models.Project{Name: "Test", OrganizationID: &org.ID}
 	config.DB.Create(&project)
 	sprint := models.Sprint{
 		ProjectID:      project.ID,
@@ -553,11 +663,12 @@ func TestDeleteTask(t *testing.T) {
 		EndDate:        time.Now().AddDate(0, 0, 7),
 	}
 	config.DB.Create(&sprint)
-	task := models.Task{Title: "Test", Status: "todo", SprintID: sprint.ID, Estimation: 5.0}
+	task := models.Task{Title: "Test", Status: "todo", SprintID: &sprint.ID, Estimation: 5.0}
 	config.DB.Create(&task)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
 	router.DELETE("/tasks/:id", DeleteTask)
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/tasks/%d", task.ID), nil)
@@ -572,3 +683,88 @@ func TestDeleteTask(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
 }
+
+func TestUpdateTaskEstimationInActiveSprintRecordsScopeChange(t *testing.T) {
+	setupTaskTestDB()
+	defer teardownTaskTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := models.Sprint{
+		ProjectID:      project.ID,
+		Name:           "Sprint 1",
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "active",
+	}
+	config.DB.Create(&sprint)
+	task := models.Task{Title: "Test", Status: "todo", SprintID: &sprint.ID, Estimation: 5.0}
+	config.DB.Create(&task)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.PUT("/tasks/:id/estimation", UpdateTaskEstimation)
+
+	jsonData, _ := json.Marshal(map[string]float64{"estimation": 8})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/tasks/%d/estimation", task.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updatedTask models.Task
+	config.DB.First(&updatedTask, task.ID)
+	assert.Equal(t, 8.0, updatedTask.Estimation)
+
+	var change models.SprintScopeChange
+	assert.NoError(t, config.DB.Where("task_id = ?", task.ID).First(&change).Error)
+	assert.Equal(t, "re_estimated", change.Type)
+	assert.Equal(t, 5.0, change.PreviousEstimation)
+	assert.Equal(t, 3.0, change.Delta)
+}
+
+func TestCreateTaskInPlannedSprintDoesNotRecordScopeChange(t *testing.T) {
+	setupTaskTestDB()
+	defer teardownTaskTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+	sprint := models.Sprint{
+		ProjectID:      project.ID,
+		Name:           "Sprint 1",
+		EstimationType: "hour",
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 0, 7),
+		Status:         "planned",
+	}
+	config.DB.Create(&sprint)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.POST("/tasks", CreateTask)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"title":      "Planned Task",
+		"status":     "todo",
+		"sprint_id":  sprint.ID,
+		"estimation": 3,
+	})
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.SprintScopeChange{}).Where("sprint_id = ?", sprint.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
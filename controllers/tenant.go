@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/models"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// tenantOrganizationIDs mengembalikan organisasi user yang sedang login dari middleware
+// TenantScope. Tanpa middleware tersebut hasilnya kosong, sehingga route yang lupa
+// memasangnya tidak melihat data tenant mana pun.
+func tenantOrganizationIDs(c *gin.Context) []uint {
+	value, ok := c.Get("organization_ids")
+	if !ok {
+		slog.WarnContext(c.Request.Context(), "Route tenant dipanggil tanpa TenantScope", "route", c.FullPath())
+		return []uint{}
+	}
+	ids, _ := value.([]uint)
+	return ids
+}
+
+// tenantTwoFactor melaporkan apakah user yang sedang login sudah mengaktifkan 2FA
+// (diisi AuthMiddleware). Tanpa nilai tersebut user dianggap belum memakai 2FA.
+func tenantTwoFactor(c *gin.Context) bool {
+	return c.GetBool("two_factor_enabled")
+}
+
+// tenantProjects membatasi query ke tabel projects pada organisasi user. Project yang
+// mewajibkan 2FA tidak terlihat oleh user yang belum mengaktifkan 2FA.
+func tenantProjects(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("projects.organization_id IN ?", tenantOrganizationIDs(c))
+		if !tenantTwoFactor(c) {
+			tx = tx.Where("projects.require_two_factor = ?", false)
+		}
+		return tx
+	}
+}
+
+// tenantRecords membatasi query ke tabel dengan kolom project_id (misalnya tasks dan
+// sprints) pada project milik organisasi user. Record di luar tenant diperlakukan
+// seperti tidak ada, sehingga handler membalas 404.
+func tenantRecords(c *gin.Context, table string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(table+".project_id IN (?)",
+			db(c).Model(&models.Project{}).Select("id").Scopes(tenantProjects(c)))
+	}
+}
+
+// requireOrgMembers memastikan semua user adalah anggota organisasi project. Project
+// tanpa organisasi tidak dibatasi.
+func requireOrgMembers(tx *gorm.DB, orgID *uint, field string, userIDs ...uint) error {
+	if orgID == nil || len(userIDs) == 0 {
+		return nil
+	}
+	var members int64
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id IN ?", *orgID, userIDs).Count(&members).Error; err != nil {
+		return err
+	}
+	if int(members) != countUnique(userIDs) {
+		return apperror.Validation(apperror.FieldError{Field: field, Code: "orgmember"})
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/metrics"
+	"kanban/models"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// accountLoginPolicy: backoff setelah beberapa gagal, lalu lockout sementara
+func accountLoginPolicy() models.LoginPolicy {
+	return models.LoginPolicy{
+		BackoffAfter: config.GetEnvInt("LOGIN_BACKOFF_AFTER", 3),
+		BackoffBase:  config.GetEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		BackoffMax:   config.GetEnvDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+		LockoutAfter: config.GetEnvInt("LOGIN_LOCKOUT_AFTER", 10),
+		LockoutFor:   config.GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		Window:       config.GetEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+	}
+}
+
+// ipLoginPolicy lebih longgar dan tanpa lockout, karena satu IP bisa dipakai
+// bersama banyak user (NAT/kantor)
+func ipLoginPolicy() models.LoginPolicy {
+	policy := accountLoginPolicy()
+	policy.BackoffAfter = config.GetEnvInt("LOGIN_IP_BACKOFF_AFTER", 20)
+	policy.LockoutAfter = 0
+	return policy
+}
+
+func accountThrottleKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// checkLoginThrottle menolak percobaan login jika akun atau IP sedang diblokir.
+// Jika ditolak, error 429 (dengan header Retry-After) didaftarkan ke context dan handler harus langsung return.
+func checkLoginThrottle(c *gin.Context, username string) (models.LoginThrottle, bool) {
+	account, ip, err := loginThrottles(c, username)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return account, false
+	}
+
+	now := time.Now()
+	wait := max(account.BlockedFor(now), ip.BlockedFor(now))
+	if wait == 0 {
+		return account, true
+	}
+
+	code := apperror.CodeTooManyLoginAttempts
+	if account.Locked && account.BlockedFor(now) > 0 {
+		code = apperror.CodeAccountLocked
+	}
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.Error(apperror.TooManyRequests(code).WithDetails(map[string]interface{}{"retry_after": retryAfter}))
+	return account, false
+}
+
+// loginThrottles memuat catatan throttle akun dan IP. Catatan yang belum ada
+// dikembalikan kosong (tidak diblokir).
+func loginThrottles(c *gin.Context, username string) (account, ip models.LoginThrottle, err error) {
+	var rows []models.LoginThrottle
+	err = db(c).Where("(scope = ? AND `key` = ?) OR (scope = ? AND `key` = ?)",
+		models.ThrottleScopeAccount, accountThrottleKey(username),
+		models.ThrottleScopeIP, c.ClientIP()).Find(&rows).Error
+	for _, row := range rows {
+		if row.Scope == models.ThrottleScopeAccount {
+			account = row
+		} else {
+			ip = row
+		}
+	}
+	return account, ip, err
+}
+
+// recordLoginFailure menaikkan hitungan gagal untuk akun dan IP request ini
+func recordLoginFailure(c *gin.Context, username string) error {
+	now := time.Now()
+	return db(c).Transaction(func(tx *gorm.DB) error {
+		if err := registerThrottleFailure(tx, models.ThrottleScopeIP, c.ClientIP(), now, ipLoginPolicy()); err != nil {
+			return err
+		}
+		return registerThrottleFailure(tx, models.ThrottleScopeAccount, accountThrottleKey(username), now, accountLoginPolicy())
+	})
+}
+
+func registerThrottleFailure(tx *gorm.DB, scope, key string, now time.Time, policy models.LoginPolicy) error {
+	// Baris dibuat dulu (jika belum ada) lalu dikunci, agar request paralel tidak saling menimpa hitungan
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.LoginThrottle{Scope: scope, Key: key, LastFailureAt: now}).Error; err != nil {
+		return err
+	}
+
+	var throttle models.LoginThrottle
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ? AND `key` = ?", scope, key).First(&throttle).Error; err != nil {
+		return err
+	}
+
+	if throttle.RegisterFailure(now, policy) {
+		metrics.LoginLockoutsTotal.Inc()
+		slog.WarnContext(tx.Statement.Context, "Akun dikunci karena terlalu banyak login gagal", "username", key, "failures", throttle.Failures)
+	}
+	return tx.Save(&throttle).Error
+}
+
+// clearAccountThrottle menghapus hitungan gagal dan lockout sebuah akun
+func clearAccountThrottle(tx *gorm.DB, username string) error {
+	return tx.Where("scope = ? AND `key` = ?", models.ThrottleScopeAccount, accountThrottleKey(username)).
+		Delete(&models.LoginThrottle{}).Error
+}
+
+// PurgeLoginThrottles menghapus catatan throttle yang login gagal terakhirnya sudah di luar
+// LOGIN_FAILURE_WINDOW dan tidak sedang memblokir. Catatan seperti ini sudah tidak berpengaruh
+// karena hitungannya direset pada gagal berikutnya.
+func PurgeLoginThrottles(database *gorm.DB, now time.Time) (int64, error) {
+	window := accountLoginPolicy().Window
+	if window <= 0 {
+		return 0, nil
+	}
+	result := database.Where("last_failure_at < ? AND (blocked_until IS NULL OR blocked_until <= ?)", now.Add(-window), now).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
+
+// RunLoginThrottlePurge menjalankan PurgeLoginThrottles setiap LOGIN_FAILURE_WINDOW sampai ctx selesai
+func RunLoginThrottlePurge(ctx context.Context) {
+	window := accountLoginPolicy().Window
+	if window <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		if deleted, err := PurgeLoginThrottles(config.DB.WithContext(ctx), time.Now()); err != nil {
+			slog.ErrorContext(ctx, "Gagal menghapus catatan login gagal yang kedaluwarsa", "error", err)
+		} else if deleted > 0 {
+			slog.InfoContext(ctx, "Catatan login gagal yang kedaluwarsa dihapus", "rows", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetMyTokens mendapatkan token akses personal milik user yang sedang login, termasuk
+// yang sudah dicabut atau kedaluwarsa. Token mentah tidak pernah ditampilkan lagi.
+func GetMyTokens(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var tokens []models.PersonalAccessToken
+	if err := db(c).Where("user_id = ?", user.ID).Order("id DESC").Find(&tokens).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// CreateMyToken membuat token akses personal. Token mentah hanya dikembalikan di
+// response ini; yang disimpan hanya hash-nya.
+func CreateMyToken(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input models.CreatePersonalAccessTokenRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	days := config.GetEnvInt("PAT_DEFAULT_EXPIRY_DAYS", 90)
+	if input.ExpiresInDays != nil {
+		days = *input.ExpiresInDays
+	}
+	expiresAt := time.Now().AddDate(0, 0, days)
+
+	token, raw, err := models.NewPersonalAccessToken(user.ID, input.Name, input.Scopes, &expiresAt)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := db(c).Create(&token).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": token, "token": raw})
+}
+
+// RevokeMyToken mencabut token akses personal. Record tetap disimpan agar
+// riwayat pemakaiannya masih terlihat di GET /me/tokens.
+func RevokeMyToken(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var token models.PersonalAccessToken
+	if err := db(c).Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&token).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeNotFound))
+		return
+	}
+
+	if token.RevokedAt == nil {
+		now := time.Now()
+		if err := db(c).Model(&token).Update("revoked_at", now).Error; err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+// revokePersonalAccessTokens mencabut semua token akses personal user yang masih berlaku
+func revokePersonalAccessTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
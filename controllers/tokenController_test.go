@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupTokenTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.PersonalAccessToken{})
+
+	config.DB = db
+}
+
+func teardownTokenTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE personal_access_tokens")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func tokenRequest(router *gin.Engine, method, path, token string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	return resp, response
+}
+
+func TestPersonalAccessTokenLifecycle(t *testing.T) {
+	setupTokenTestDB()
+	defer teardownTokenTestDB()
+
+	config.DB.Create(&models.User{Username: "ci-bot", Password: "hashed"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/me/tokens", asUser("ci-bot"), CreateMyToken)
+	router.GET("/me/tokens", asUser("ci-bot"), GetMyTokens)
+	router.DELETE("/me/tokens/:id", asUser("ci-bot"), RevokeMyToken)
+	router.GET("/me", middlewares.AuthMiddleware(), GetMe)
+	created := func(c *gin.Context) { c.Status(http.StatusCreated) }
+	router.POST("/tasks", middlewares.AuthMiddleware(), created)
+	router.POST("/projects", middlewares.AuthMiddleware(), created)
+
+	resp, response := tokenRequest(router, "POST", "/me/tokens", "", map[string]interface{}{"name": "reader", "scopes": []string{"read"}})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	raw := response["token"].(string)
+	assert.True(t, strings.HasPrefix(raw, models.PersonalAccessTokenPrefix))
+	id := response["data"].(map[string]interface{})["id"]
+	assert.NotNil(t, response["data"].(map[string]interface{})["expires_at"])
+
+	// Hanya hash yang disimpan
+	var stored models.PersonalAccessToken
+	config.DB.First(&stored)
+	assert.Equal(t, models.HashToken(raw), stored.TokenHash)
+	assert.Nil(t, stored.LastUsedAt)
+
+	resp, response = tokenRequest(router, "GET", "/me", raw, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "ci-bot", response["data"].(map[string]interface{})["username"])
+
+	config.DB.First(&stored)
+	assert.NotNil(t, stored.LastUsedAt)
+	assert.NotEmpty(t, stored.LastUsedIP)
+
+	// Scope read tidak boleh menulis
+	resp, response = tokenRequest(router, "POST", "/tasks", raw, nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "insufficient_scope", response["code"])
+
+	resp, response = tokenRequest(router, "GET", "/me/tokens", "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+	assert.Nil(t, response["data"].([]interface{})[0].(map[string]interface{})["token_hash"])
+
+	resp, _ = tokenRequest(router, "DELETE", fmt.Sprintf("/me/tokens/%v", id), "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp, response = tokenRequest(router, "GET", "/me", raw, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "invalid_token", response["code"])
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	setupTokenTestDB()
+	defer teardownTokenTestDB()
+
+	config.DB.Create(&models.User{Username: "ci-bot", Password: "hashed"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/me/tokens", asUser("ci-bot"), CreateMyToken)
+	created := func(c *gin.Context) { c.Status(http.StatusCreated) }
+	router.POST("/tasks", middlewares.AuthMiddleware(), created)
+	router.POST("/projects", middlewares.AuthMiddleware(), created)
+
+	resp, response := tokenRequest(router, "POST", "/me/tokens", "", map[string]interface{}{"name": "bad", "scopes": []string{"everything"}})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "tokenscope", response["errors"].([]interface{})[0].(map[string]interface{})["code"])
+
+	_, response = tokenRequest(router, "POST", "/me/tokens", "", map[string]interface{}{"name": "writer", "scopes": []string{"tasks:write"}})
+	writer := response["token"].(string)
+	_, response = tokenRequest(router, "POST", "/me/tokens", "", map[string]interface{}{"name": "admin", "scopes": []string{"admin"}})
+	admin := response["token"].(string)
+
+	resp, _ = tokenRequest(router, "POST", "/tasks", writer, nil)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	resp, _ = tokenRequest(router, "POST", "/projects", writer, nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	resp, _ = tokenRequest(router, "POST", "/projects", admin, nil)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	// Token kedaluwarsa ditolak
+	config.DB.Model(&models.PersonalAccessToken{}).Where("name = ?", "admin").Update("expires_at", "2000-01-01 00:00:00")
+	resp, _ = tokenRequest(router, "POST", "/projects", admin, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -0,0 +1,350 @@
+package controllers
+
+import (
+	"context"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/models"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeleteProject memindahkan project beserta sprint dan task-nya ke tempat sampah.
+// Hanya pemilik project, admin organisasi project atau administrator sistem.
+func DeleteProject(c *gin.Context) {
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Scopes(tenantProjects(c)).Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+		if err := requireProjectAdmin(c, tx, &project); err != nil {
+			return err
+		}
+
+		// Semua record memakai deleted_at yang sama agar restore project hanya
+		// memulihkan yang ikut terhapus bersamanya
+		now := time.Now()
+		for _, model := range []interface{}{&models.Task{}, &models.Sprint{}} {
+			if err := tx.Model(model).Where("project_id = ?", project.ID).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&project).Update("deleted_at", now).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project moved to trash"})
+}
+
+// DeleteSprint memindahkan sprint beserta task-nya ke tempat sampah. Sprint yang sedang
+// aktif harus diselesaikan terlebih dahulu. Hanya pemilik project, admin organisasi
+// project atau administrator sistem.
+func DeleteSprint(c *gin.Context) {
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		var sprint models.Sprint
+		if err := lockSprint(tx.Scopes(tenantRecords(c, "sprints")), c.Param("id"), &sprint); err != nil {
+			return err
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, nil); err != nil {
+			return err
+		}
+		if err := requireProjectAdminByID(c, tx, sprint.ProjectID); err != nil {
+			return err
+		}
+		if sprint.Status == models.SprintStatusActive {
+			return apperror.Conflict(apperror.CodeSprintActive)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.Task{}).Where("sprint_id = ?", sprint.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&sprint).Update("deleted_at", now).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint moved to trash"})
+}
+
+// GetProjectTrash mendapatkan task dan sprint project yang sudah dihapus dan masih bisa dipulihkan
+func GetProjectTrash(c *gin.Context) {
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+
+	trash := models.ProjectTrash{
+		ProjectID:     project.ID,
+		RetentionDays: int(trashRetention() / (24 * time.Hour)),
+		Tasks:         []models.Task{},
+		Sprints:       []models.Sprint{},
+	}
+	if err := db(c).Unscoped().Where("project_id = ? AND deleted_at IS NOT NULL", project.ID).
+		Order("deleted_at DESC, id").Find(&trash.Tasks).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := db(c).Unscoped().Where("project_id = ? AND deleted_at IS NOT NULL", project.ID).
+		Order("deleted_at DESC, id").Find(&trash.Sprints).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": trash})
+}
+
+// GetDeletedProjects mendapatkan project yang sudah dihapus dan masih bisa dipulihkan
+func GetDeletedProjects(c *gin.Context) {
+	projects := []models.Project{}
+	if err := db(c).Unscoped().Scopes(tenantProjects(c)).Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id").Find(&projects).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": projects})
+}
+
+// RestoreProject memulihkan project beserta sprint dan task yang ikut terhapus bersamanya.
+// Task atau sprint yang sudah dihapus sendiri sebelumnya tetap di tempat sampah.
+func RestoreProject(c *gin.Context) {
+	var project models.Project
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Scopes(tenantProjects(c)).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL").First(&project, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+		if err := requireProjectAdmin(c, tx, &project); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.Task{}, &models.Sprint{}} {
+			if err := tx.Unscoped().Model(model).Where("project_id = ? AND deleted_at = ?", project.ID, project.DeletedAt.Time).
+				Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(&project).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db(c).Preload("UserParticipants").First(&project, project.ID)
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// RestoreSprint memulihkan sprint beserta task yang ikut terhapus bersamanya. Project
+// sprint harus masih ada; project yang dihapus dipulihkan lewat RestoreProject. Hanya
+// pemilik project, admin organisasi project atau administrator sistem.
+func RestoreSprint(c *gin.Context) {
+	var sprint models.Sprint
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Scopes(tenantRecords(c, "sprints")).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL").First(&sprint, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeSprintNotFound)
+		}
+		if err := ensureWritable(tx, sprint.ProjectID, nil); err != nil {
+			return err
+		}
+		if err := requireProjectAdminByID(c, tx, sprint.ProjectID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Task{}).Where("sprint_id = ? AND deleted_at = ?", sprint.ID, sprint.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&sprint).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db(c).Preload("Tasks").First(&sprint, sprint.ID)
+	c.JSON(http.StatusOK, gin.H{"data": sprint})
+}
+
+// RestoreTask memulihkan task yang dihapus. Jika sprint-nya sudah tidak ada, task
+// dikembalikan ke urutan terbawah backlog project.
+func RestoreTask(c *gin.Context) {
+	var task models.Task
+	err := db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Scopes(tenantRecords(c, "tasks")).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL").First(&task, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeTaskNotFound)
+		}
+		if task.SprintID != nil {
+			var sprints int64
+			if err := tx.Model(&models.Sprint{}).Where("id = ?", *task.SprintID).Count(&sprints).Error; err != nil {
+				return err
+			}
+			if sprints == 0 {
+				rank, err := nextBacklogRank(tx, task.ProjectID)
+				if err != nil {
+					return err
+				}
+				task.SprintID = nil
+				task.BacklogRank = rank
+			}
+		}
+		if err := ensureWritable(tx, task.ProjectID, task.SprintID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&task).Updates(map[string]interface{}{
+			"deleted_at":   nil,
+			"sprint_id":    task.SprintID,
+			"backlog_rank": task.BacklogRank,
+		}).Error; err != nil {
+			return err
+		}
+		return recordScopeChange(tx, task.SprintID, &task, models.ScopeChangeAdded, 0, task.Estimation)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db(c).First(&task, task.ID)
+	c.JSON(http.StatusOK, gin.H{"data": task})
+}
+
+// PurgeTrash menghapus permanen project, sprint dan task yang sudah di tempat sampah
+// sejak sebelum cutoff, beserta data turunannya (riwayat status, carry-over, kapasitas,
+// snapshot, scope change, participant dan undangan)
+func PurgeTrash(database *gorm.DB, cutoff time.Time) (models.TrashPurgeResult, error) {
+	var result models.TrashPurgeResult
+	err := database.Transaction(func(tx *gorm.DB) error {
+		projects := tx.Unscoped().Model(&models.Project{}).Select("id").Where("deleted_at < ?", cutoff)
+		sprints := tx.Unscoped().Model(&models.Sprint{}).Select("id").Where("deleted_at < ? OR project_id IN (?)", cutoff, projects)
+		tasks := tx.Unscoped().Model(&models.Task{}).Select("id").Where("deleted_at < ? OR project_id IN (?)", cutoff, projects)
+
+		// Data turunan dihapus lebih dulu selagi subquery di atas masih menemukan induknya
+		children := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&models.TaskStatusTransition{}, "task_id IN (?)", []interface{}{tasks}},
+			{&models.TaskCarryOver{}, "task_id IN (?) OR from_sprint_id IN (?) OR to_sprint_id IN (?)", []interface{}{tasks, sprints, sprints}},
+			{&models.SprintScopeChange{}, "task_id IN (?) OR sprint_id IN (?)", []interface{}{tasks, sprints}},
+			{&models.SprintCapacity{}, "sprint_id IN (?)", []interface{}{sprints}},
+			{&models.SprintSnapshot{}, "sprint_id IN (?)", []interface{}{sprints}},
+			{&models.ProjectInvitation{}, "project_id IN (?)", []interface{}{projects}},
+		}
+		for _, child := range children {
+			if err := tx.Where(child.query, child.args...).Delete(child.model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM project_users WHERE project_id IN (?)", projects).Error; err != nil {
+			return err
+		}
+
+		deleted := tx.Unscoped().Where("deleted_at < ? OR project_id IN (?)", cutoff, projects).Delete(&models.Task{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		result.Tasks = deleted.RowsAffected
+
+		deleted = tx.Unscoped().Where("deleted_at < ? OR project_id IN (?)", cutoff, projects).Delete(&models.Sprint{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		result.Sprints = deleted.RowsAffected
+
+		deleted = tx.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.Project{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		result.Projects = deleted.RowsAffected
+		return nil
+	})
+	return result, err
+}
+
+// RunTrashPurge menjalankan PurgeTrash setiap TRASH_PURGE_INTERVAL sampai ctx selesai.
+// TRASH_RETENTION 0 mematikan purge sehingga tempat sampah disimpan selamanya.
+func RunTrashPurge(ctx context.Context) {
+	retention := trashRetention()
+	if retention <= 0 {
+		slog.Info("Purge tempat sampah dimatikan")
+		return
+	}
+
+	ticker := time.NewTicker(config.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour))
+	defer ticker.Stop()
+	for {
+		result, err := PurgeTrash(config.DB.WithContext(ctx), time.Now().Add(-retention))
+		if err != nil {
+			slog.ErrorContext(ctx, "Gagal menghapus permanen isi tempat sampah", "error", err)
+		} else if result.Projects+result.Sprints+result.Tasks > 0 {
+			slog.InfoContext(ctx, "Isi tempat sampah dihapus permanen",
+				"projects", result.Projects, "sprints", result.Sprints, "tasks", result.Tasks)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// trashRetention mengembalikan lama record disimpan di tempat sampah (TRASH_RETENTION)
+func trashRetention() time.Duration {
+	return config.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+}
+
+// requireProjectAdmin memastikan user yang sedang login boleh menghapus, memulihkan atau
+// mengubah pengaturan keamanan project: pemiliknya, admin organisasi project, atau
+// administrator sistem
+func requireProjectAdmin(c *gin.Context, tx *gorm.DB, project *models.Project) error {
+	if c.GetBool("is_admin") {
+		return nil
+	}
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if project.OwnerID != nil && *project.OwnerID == user.ID {
+		return nil
+	}
+	if project.OrganizationID != nil {
+		var admins int64
+		if err := tx.Model(&models.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ? AND role = ?", *project.OrganizationID, user.ID, models.OrgRoleAdmin).
+			Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return nil
+		}
+	}
+	return apperror.Forbidden(apperror.CodeForbidden)
+}
+
+// requireProjectAdminByID seperti requireProjectAdmin untuk record yang hanya menyimpan
+// ID project-nya, misalnya sprint
+func requireProjectAdminByID(c *gin.Context, tx *gorm.DB, projectID uint) error {
+	var project models.Project
+	if err := tx.First(&project, projectID).Error; err != nil {
+		return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+	}
+	return requireProjectAdmin(c, tx, &project)
+}
@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupTrashTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.ProjectUser{}, &models.Sprint{}, &models.Task{},
+		&models.TaskStatusTransition{}, &models.TaskCarryOver{}, &models.SprintScopeChange{},
+		&models.SprintCapacity{}, &models.SprintSnapshot{}, &models.ProjectInvitation{}, &models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownTrashTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("TRUNCATE TABLE project_invitations")
+		config.DB.Exec("TRUNCATE TABLE sprint_snapshots")
+		config.DB.Exec("TRUNCATE TABLE sprint_capacities")
+		config.DB.Exec("TRUNCATE TABLE sprint_scope_changes")
+		config.DB.Exec("TRUNCATE TABLE task_carry_overs")
+		config.DB.Exec("TRUNCATE TABLE task_status_transitions")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func setupTrashRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+
+	auth := router.Group("/")
+	auth.Use(middlewares.AuthMiddleware(), middlewares.TenantScope())
+	auth.DELETE("/projects/:id", DeleteProject)
+	auth.GET("/projects/trash", GetDeletedProjects)
+	auth.POST("/projects/:id/restore", RestoreProject)
+	auth.GET("/projects/:id/trash", GetProjectTrash)
+	auth.DELETE("/sprints/:id", DeleteSprint)
+	auth.POST("/sprints/:id/restore", RestoreSprint)
+	auth.DELETE("/tasks/:id", DeleteTask)
+	auth.POST("/tasks/:id/restore", RestoreTask)
+	return router
+}
+
+func TestTrashProjectCascade(t *testing.T) {
+	setupTrashTestDB()
+	defer teardownTrashTestDB()
+
+	owner := models.User{Username: "owner", Password: "hashed"}
+	other := models.User{Username: "other", Password: "hashed"}
+	config.DB.Create(&owner)
+	config.DB.Create(&other)
+	org := seedTenant(owner, other)
+	project := models.Project{Name: "Rocket", OrganizationID: &org.ID, OwnerID: &owner.ID, UserParticipants: []models.User{owner, other}}
+	config.DB.Create(&project)
+	sprint := models.Sprint{ProjectID: project.ID, Name: "Sprint 1", EstimationType: models.EstimationTypeHour, Status: models.SprintStatusPlanned}
+	config.DB.Create(&sprint)
+	kept := models.Task{Title: "Kept", Status: models.TaskStatusTodo, ProjectID: project.ID, SprintID: &sprint.ID}
+	removedEarlier := models.Task{Title: "Removed earlier", Status: models.TaskStatusTodo, ProjectID: project.ID}
+	config.DB.Create(&kept)
+	config.DB.Create(&removedEarlier)
+	router := setupTrashRouter()
+
+	resp, _ := adminRequest(router, "DELETE", fmt.Sprintf("/tasks/%d", removedEarlier.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Hanya pemilik project yang bisa menghapus
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/projects/%d", project.ID), "other", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/projects/%d", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var liveTasks, liveSprints int64
+	config.DB.Model(&models.Task{}).Where("project_id = ?", project.ID).Count(&liveTasks)
+	config.DB.Model(&models.Sprint{}).Where("project_id = ?", project.ID).Count(&liveSprints)
+	assert.Equal(t, int64(0), liveTasks)
+	assert.Equal(t, int64(0), liveSprints)
+
+	resp, response := adminRequest(router, "GET", "/projects/trash", "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"], 1)
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/projects/%d/restore", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Task yang dihapus sendiri sebelumnya tetap di tempat sampah
+	resp, response = adminRequest(router, "GET", fmt.Sprintf("/projects/%d/trash", project.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	trash := response["data"].(map[string]interface{})
+	assert.Len(t, trash["sprints"], 0)
+	if assert.Len(t, trash["tasks"], 1) {
+		assert.Equal(t, "Removed earlier", trash["tasks"].([]interface{})[0].(map[string]interface{})["title"])
+	}
+
+	var restored models.Task
+	assert.NoError(t, config.DB.First(&restored, kept.ID).Error)
+	assert.Equal(t, sprint.ID, *restored.SprintID)
+}
+
+func TestTrashRestoreTaskOfDeletedSprint(t *testing.T) {
+	setupTrashTestDB()
+	defer teardownTrashTestDB()
+
+	owner := models.User{Username: "owner", Password: "hashed"}
+	other := models.User{Username: "other", Password: "hashed"}
+	config.DB.Create(&owner)
+	config.DB.Create(&other)
+	org := seedTenant(owner, other)
+	project := models.Project{Name: "Rocket", OrganizationID: &org.ID, OwnerID: &owner.ID, UserParticipants: []models.User{owner, other}}
+	config.DB.Create(&project)
+	sprint := models.Sprint{ProjectID: project.ID, Name: "Sprint 1", EstimationType: models.EstimationTypeHour, Status: models.SprintStatusPlanned}
+	config.DB.Create(&sprint)
+	backlog := models.Task{Title: "Backlog", Status: models.TaskStatusTodo, ProjectID: project.ID, BacklogRank: 1}
+	task := models.Task{Title: "In sprint", Status: models.TaskStatusTodo, ProjectID: project.ID, SprintID: &sprint.ID}
+	config.DB.Create(&backlog)
+	config.DB.Create(&task)
+	router := setupTrashRouter()
+
+	// Sprint aktif harus diselesaikan sebelum dihapus
+	config.DB.Model(&sprint).Update("status", models.SprintStatusActive)
+	resp, response := adminRequest(router, "DELETE", fmt.Sprintf("/sprints/%d", sprint.ID), "owner", nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "sprint_active", response["code"])
+
+	config.DB.Model(&sprint).Update("status", models.SprintStatusPlanned)
+
+	// Hanya pemilik project yang bisa menghapus dan memulihkan sprint
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/sprints/%d", sprint.ID), "other", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, _ = adminRequest(router, "DELETE", fmt.Sprintf("/sprints/%d", sprint.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Task bisa dipulihkan sendiri tanpa sprint-nya dan masuk ke backlog
+	resp, response = adminRequest(router, "POST", fmt.Sprintf("/tasks/%d/restore", task.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	data := response["data"].(map[string]interface{})
+	assert.Nil(t, data["sprint_id"])
+	assert.Equal(t, float64(2), data["backlog_rank"])
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/tasks/%d/restore", task.ID), "owner", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, _ = adminRequest(router, "POST", fmt.Sprintf("/sprints/%d/restore", sprint.ID), "other", nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp, response = adminRequest(router, "POST", fmt.Sprintf("/sprints/%d/restore", sprint.ID), "owner", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, response["data"].(map[string]interface{})["tasks"], 0)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	setupTrashTestDB()
+	defer teardownTrashTestDB()
+
+	project := models.Project{Name: "Old"}
+	config.DB.Create(&project)
+	sprint := models.Sprint{ProjectID: project.ID, Name: "Sprint 1", EstimationType: models.EstimationTypeHour, Status: models.SprintStatusPlanned}
+	config.DB.Create(&sprint)
+	task := models.Task{Title: "Old task", Status: models.TaskStatusTodo, ProjectID: project.ID, SprintID: &sprint.ID}
+	config.DB.Create(&task)
+	config.DB.Create(&models.TaskStatusTransition{TaskID: task.ID, ProjectID: project.ID, SprintID: &sprint.ID, ToStatus: models.TaskStatusTodo})
+	config.DB.Create(&models.SprintCapacity{SprintID: sprint.ID, UserID: 1, Capacity: 10})
+
+	live := models.Project{Name: "Live"}
+	config.DB.Create(&live)
+	recent := models.Task{Title: "Recently deleted", Status: models.TaskStatusTodo, ProjectID: live.ID}
+	config.DB.Create(&recent)
+	config.DB.Delete(&recent)
+
+	deletedAt := time.Now().Add(-40 * 24 * time.Hour)
+	config.DB.Model(&models.Task{}).Where("id = ?", task.ID).Update("deleted_at", deletedAt)
+	config.DB.Model(&models.Sprint{}).Where("id = ?", sprint.ID).Update("deleted_at", deletedAt)
+	config.DB.Model(&project).Update("deleted_at", deletedAt)
+
+	result, err := PurgeTrash(config.DB, time.Now().Add(-30*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, models.TrashPurgeResult{Projects: 1, Sprints: 1, Tasks: 1}, result)
+
+	var remaining int64
+	config.DB.Unscoped().Model(&models.Project{}).Where("id = ?", project.ID).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+	config.DB.Model(&models.TaskStatusTransition{}).Where("task_id = ?", task.ID).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+	config.DB.Model(&models.SprintCapacity{}).Where("sprint_id = ?", sprint.ID).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+
+	// Task yang belum melewati masa retensi masih bisa dipulihkan
+	config.DB.Unscoped().Model(&models.Task{}).Where("id = ?", recent.ID).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+}
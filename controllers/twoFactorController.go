@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"errors"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+	"kanban/twofactor"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetupTwoFactor membuat secret TOTP baru untuk user. 2FA belum aktif sampai kode
+// pertama diverifikasi lewat POST /me/2fa/enable.
+func SetupTwoFactor(c *gin.Context) {
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if user.TOTPEnabled {
+		c.Error(apperror.Conflict(apperror.CodeTwoFactorEnabled))
+		return
+	}
+
+	secret, err := twofactor.GenerateSecret()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if err := db(c).Model(user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	issuer := config.GetEnv("TOTP_ISSUER", "Kanban")
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": twofactor.ProvisioningURI(issuer, user.Username, secret),
+	})
+}
+
+// EnableTwoFactor mengaktifkan 2FA setelah kode dari aplikasi authenticator cocok,
+// lalu mengembalikan recovery code yang hanya ditampilkan sekali
+func EnableTwoFactor(c *gin.Context) {
+	var input models.TwoFactorCodeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var codes []string
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		locked, err := lockUser(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if locked.TOTPEnabled {
+			return apperror.Conflict(apperror.CodeTwoFactorEnabled)
+		}
+		if locked.TOTPSecret == "" {
+			return apperror.Conflict(apperror.CodeTwoFactorNotEnabled)
+		}
+
+		step, ok := twofactor.Validate(locked.TOTPSecret, input.Code, time.Now(), locked.TOTPLastStep)
+		if !ok {
+			return apperror.Validation(apperror.FieldError{Field: "code", Code: "twofactorcode"})
+		}
+		if err := tx.Model(&locked).Updates(map[string]interface{}{"totp_enabled": true, "totp_last_step": step}).Error; err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recovery_codes": codes})
+}
+
+// DisableTwoFactor mematikan 2FA setelah kode TOTP atau recovery code diverifikasi.
+// Ditolak jika user masih menjadi participant project yang mewajibkan 2FA.
+func DisableTwoFactor(c *gin.Context) {
+	var input models.TwoFactorCodeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		locked, err := lockUser(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !locked.TOTPEnabled {
+			return apperror.Conflict(apperror.CodeTwoFactorNotEnabled)
+		}
+
+		var projectIDs []uint
+		if err := tx.Table("project_users").
+			Joins("JOIN projects ON projects.id = project_users.project_id AND projects.deleted_at IS NULL").
+			Where("project_users.user_id = ? AND projects.require_two_factor = ?", user.ID, true).
+			Pluck("project_users.project_id", &projectIDs).Error; err != nil {
+			return err
+		}
+		if len(projectIDs) > 0 {
+			return apperror.Conflict(apperror.CodeTwoFactorRequiredBy).
+				WithDetails(map[string]interface{}{"project_ids": projectIDs})
+		}
+
+		if ok, _, err := verifySecondFactor(tx, &locked, input.Code); err != nil {
+			return err
+		} else if !ok {
+			return apperror.Validation(apperror.FieldError{Field: "code", Code: "twofactorcode"})
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&locked).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes membuat recovery code baru dan membatalkan semua yang lama
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var input models.TwoFactorCodeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var codes []string
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		locked, err := lockUser(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !locked.TOTPEnabled {
+			return apperror.Conflict(apperror.CodeTwoFactorNotEnabled)
+		}
+
+		step, ok := twofactor.Validate(locked.TOTPSecret, input.Code, time.Now(), locked.TOTPLastStep)
+		if !ok {
+			return apperror.Validation(apperror.FieldError{Field: "code", Code: "twofactorcode"})
+		}
+		if err := tx.Model(&locked).Update("totp_last_step", step).Error; err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// LoginTwoFactor menukar challenge token dari POST /login dan kode 2FA (TOTP atau
+// recovery code) dengan JWT penuh. Kode yang salah dihitung sebagai login gagal.
+func LoginTwoFactor(c *gin.Context) {
+	var input models.LoginTwoFactorRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, username, version, err := middlewares.ParseChallengeToken(input.ChallengeToken)
+	if err != nil {
+		c.Error(&apperror.Error{Status: http.StatusUnauthorized, Code: apperror.CodeInvalidToken, Cause: err})
+		return
+	}
+
+	account, allowed := checkLoginThrottle(c, username)
+	if !allowed {
+		return
+	}
+
+	var (
+		user       models.User
+		verified   bool
+		recovery   bool
+		remaining  int64
+		invalidErr = apperror.Unauthorized(apperror.CodeInvalidToken)
+	)
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return invalidErr
+			}
+			return err
+		}
+		// Password diganti atau sesi dicabut setelah challenge dibuat
+		if user.TokenVersion != version || !user.TOTPEnabled {
+			return invalidErr
+		}
+
+		ok, usedRecovery, err := verifySecondFactor(tx, &user, input.Code)
+		if err != nil || !ok {
+			return err
+		}
+		verified, recovery = true, usedRecovery
+		if !recovery {
+			return nil
+		}
+		return tx.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !verified {
+		if err := recordLoginFailure(c, username); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		c.Error(apperror.Unauthorized(apperror.CodeInvalidTwoFactorCode))
+		return
+	}
+
+	if account.ID != 0 {
+		if err := clearAccountThrottle(db(c), username); err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+	}
+
+	token, err := middlewares.GenerateJWT(user.ID, user.Username, user.TokenVersion)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	response := gin.H{"token": token}
+	if recovery {
+		response["recovery_codes_remaining"] = remaining
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SetProjectTwoFactor mengatur apakah semua participant project wajib mengaktifkan 2FA.
+// Hanya pemilik project, admin organisasi atau admin sistem yang boleh mengubahnya;
+// untuk mengaktifkannya user tersebut dan semua participant harus sudah memakai 2FA.
+func SetProjectTwoFactor(c *gin.Context) {
+	var input models.ProjectTwoFactorRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var project models.Project
+	err = db(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(tenantProjects(c)).Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, c.Param("id")).Error; err != nil {
+			return apperror.NotFoundOr(err, apperror.CodeProjectNotFound)
+		}
+		if err := writableProject(&project); err != nil {
+			return err
+		}
+		if err := requireProjectAdmin(c, tx, &project); err != nil {
+			return err
+		}
+
+		var participants []models.User
+		if err := tx.Model(&project).Association("UserParticipants").Find(&participants); err != nil {
+			return err
+		}
+		var without []gin.H
+		for _, p := range participants {
+			if !p.TOTPEnabled {
+				without = append(without, gin.H{"id": p.ID, "username": p.Username})
+			}
+		}
+
+		if *input.Required {
+			if !user.TOTPEnabled {
+				return apperror.Forbidden(apperror.CodeTwoFactorRequired)
+			}
+			if len(without) > 0 {
+				return apperror.Conflict(apperror.CodeMembersWithoutTwoFactor).
+					WithDetails(map[string]interface{}{"participants": without})
+			}
+		}
+
+		project.RequireTwoFactor = *input.Required
+		return tx.Model(&project).Update("require_two_factor", project.RequireTwoFactor).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// lockUser mengambil user dengan SELECT ... FOR UPDATE agar kode TOTP dan recovery
+// code tidak bisa dipakai dua kali oleh request paralel
+func lockUser(tx *gorm.DB, id uint) (models.User, error) {
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error
+	if err != nil {
+		return user, apperror.NotFoundOr(err, apperror.CodeUserNotFound)
+	}
+	return user, nil
+}
+
+// verifySecondFactor mencocokkan kode TOTP, lalu recovery code jika tidak cocok.
+// Kode yang berhasil dipakai langsung ditandai sehingga tidak bisa dipakai ulang.
+// user harus sudah dikunci di dalam tx.
+func verifySecondFactor(tx *gorm.DB, user *models.User, code string) (ok, recovery bool, err error) {
+	if step, valid := twofactor.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); valid {
+		user.TOTPLastStep = step
+		return true, false, tx.Model(user).Update("totp_last_step", step).Error
+	}
+
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, models.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, false, result.Error
+	}
+	return result.RowsAffected > 0, result.RowsAffected > 0, nil
+}
+
+// replaceRecoveryCodes menghapus recovery code lama user dan menyimpan set baru
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	records, codes, err := models.NewRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
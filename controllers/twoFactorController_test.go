@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+	"kanban/twofactor"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupTwoFactorTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.User{}, &models.Project{}, &models.RecoveryCode{}, &models.LoginThrottle{},
+		&models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownTwoFactorTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE recovery_codes")
+		config.DB.Exec("TRUNCATE TABLE login_throttles")
+		config.DB.Exec("TRUNCATE TABLE project_users")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE users")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func twoFactorRequest(router *gin.Engine, method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	return resp, response
+}
+
+func TestTwoFactorEnrollmentAndLogin(t *testing.T) {
+	setupTwoFactorTestDB()
+	defer teardownTwoFactorTestDB()
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	config.DB.Create(&models.User{Username: "secure", Password: string(hashed)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/login", Login)
+	router.POST("/login/2fa", LoginTwoFactor)
+	router.GET("/me", middlewares.AuthMiddleware(), GetMe)
+	router.POST("/me/2fa/setup", asUser("secure"), SetupTwoFactor)
+	router.POST("/me/2fa/enable", asUser("secure"), EnableTwoFactor)
+
+	resp, response := twoFactorRequest(router, "POST", "/me/2fa/setup", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	secret := response["secret"].(string)
+	assert.Contains(t, response["otpauth_uri"], "otpauth://totp/Kanban:secure?")
+
+	resp, _ = twoFactorRequest(router, "POST", "/me/2fa/enable", map[string]string{"code": "000000"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	now := time.Now()
+	code, _ := twofactor.Code(secret, twofactor.Step(now))
+	resp, response = twoFactorRequest(router, "POST", "/me/2fa/enable", map[string]string{"code": code})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	recoveryCodes := response["recovery_codes"].([]interface{})
+	assert.Len(t, recoveryCodes, models.RecoveryCodeCount)
+
+	// Langkah password hanya menghasilkan challenge token
+	resp, response = twoFactorRequest(router, "POST", "/login", map[string]string{"username": "secure", "password": "password123"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, true, response["two_factor_required"])
+	assert.Nil(t, response["token"])
+	challenge := response["challenge_token"].(string)
+
+	// Challenge token tidak bisa dipakai sebagai token akses
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", challenge)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Kode yang sudah dipakai saat enable tidak bisa dipakai ulang
+	resp, response = twoFactorRequest(router, "POST", "/login/2fa", map[string]string{"challenge_token": challenge, "code": code})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "invalid_two_factor_code", response["code"])
+
+	next, _ := twofactor.Code(secret, twofactor.Step(now)+1)
+	resp, response = twoFactorRequest(router, "POST", "/login/2fa", map[string]string{"challenge_token": challenge, "code": next})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEmpty(t, response["token"])
+
+	// Recovery code sekali pakai
+	recovery := recoveryCodes[0].(string)
+	resp, response = twoFactorRequest(router, "POST", "/login/2fa", map[string]string{"challenge_token": challenge, "code": recovery})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(models.RecoveryCodeCount-1), response["recovery_codes_remaining"])
+
+	resp, _ = twoFactorRequest(router, "POST", "/login/2fa", map[string]string{"challenge_token": challenge, "code": recovery})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	var stored []models.RecoveryCode
+	config.DB.Find(&stored)
+	for _, rc := range stored {
+		assert.NotEqual(t, recovery, rc.CodeHash)
+	}
+}
+
+func TestLoginTwoFactorChallengeFollowsUserID(t *testing.T) {
+	setupTwoFactorTestDB()
+	defer teardownTwoFactorTestDB()
+
+	secret, _ := twofactor.GenerateSecret()
+	original := models.User{Username: "secure", Password: "hashed", TOTPSecret: secret, TOTPEnabled: true}
+	config.DB.Create(&original)
+	challenge, _ := middlewares.GenerateChallengeToken(original.ID, original.Username, original.TokenVersion)
+
+	// Username lama dipakai ulang user lain setelah pemiliknya mengganti username
+	config.DB.Model(&original).Update("username", "renamed")
+	impostorSecret, _ := twofactor.GenerateSecret()
+	config.DB.Create(&models.User{Username: "secure", Password: "hashed", TOTPSecret: impostorSecret, TOTPEnabled: true})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.POST("/login/2fa", LoginTwoFactor)
+
+	code, _ := twofactor.Code(impostorSecret, twofactor.Step(time.Now()))
+	resp, _ := twoFactorRequest(router, "POST", "/login/2fa", map[string]string{"challenge_token": challenge, "code": code})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	code, _ = twofactor.Code(secret, twofactor.Step(time.Now()))
+	resp, response := twoFactorRequest(router, "POST", "/login/2fa", map[string]string{"challenge_token": challenge, "code": code})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEmpty(t, response["token"])
+}
+
+// withTwoFactor menggantikan status 2FA yang biasanya diisi AuthMiddleware
+func withTwoFactor(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("two_factor_enabled", enabled)
+		c.Next()
+	}
+}
+
+func TestProjectRequireTwoFactor(t *testing.T) {
+	setupTwoFactorTestDB()
+	defer teardownTwoFactorTestDB()
+
+	secret, _ := twofactor.GenerateSecret()
+	owner := models.User{Username: "owner", Password: "hashed", TOTPSecret: secret, TOTPEnabled: true}
+	member := models.User{Username: "member", Password: "hashed"}
+	outsider := models.User{Username: "outsider", Password: "hashed"}
+	config.DB.Create(&owner)
+	config.DB.Create(&member)
+	config.DB.Create(&outsider)
+
+	org := seedTenant(owner, member, outsider)
+	project := models.Project{Name: "Secure Project", OwnerID: &owner.ID, OrganizationID: &org.ID, UserParticipants: []models.User{owner, member}}
+	config.DB.Create(&project)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.PUT("/projects/:id/two-factor", asUser("owner"), withTwoFactor(true), SetProjectTwoFactor)
+	router.PUT("/member/projects/:id/two-factor", asUser("member"), withTwoFactor(true), SetProjectTwoFactor)
+	router.GET("/member/projects/:id", asUser("member"), withTwoFactor(false), GetProjects)
+	router.POST("/projects/:id/participants", asUser("owner"), withTwoFactor(true), AddParticipant)
+	router.POST("/me/2fa/disable", asUser("owner"), withTwoFactor(true), DisableTwoFactor)
+
+	path := fmt.Sprintf("/projects/%d/two-factor", project.ID)
+	resp, response := twoFactorRequest(router, "PUT", path, map[string]bool{"required": true})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "project_members_without_two_factor", response["code"])
+
+	config.DB.Model(&member).Updates(map[string]interface{}{"totp_secret": secret, "totp_enabled": true})
+	resp, response = twoFactorRequest(router, "PUT", path, map[string]bool{"required": true})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, true, response["data"].(map[string]interface{})["require_two_factor"])
+
+	// Participant biasa tidak boleh mematikan kewajiban 2FA
+	resp, response = twoFactorRequest(router, "PUT", fmt.Sprintf("/member/projects/%d/two-factor", project.ID), map[string]bool{"required": false})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "forbidden", response["code"])
+
+	// Sesi tanpa 2FA tidak bisa membuka project yang mewajibkan 2FA
+	resp, _ = twoFactorRequest(router, "GET", fmt.Sprintf("/member/projects/%d", project.ID), nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Participant baru juga wajib memakai 2FA
+	resp, response = twoFactorRequest(router, "POST", fmt.Sprintf("/projects/%d/participants", project.ID), map[string]uint{"user_id": outsider.ID})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "twofactor", response["errors"].([]interface{})[0].(map[string]interface{})["code"])
+
+	// Participant tidak bisa mematikan 2FA selama project mewajibkannya
+	code, _ := twofactor.Code(secret, twofactor.Step(time.Now()))
+	resp, response = twoFactorRequest(router, "POST", "/me/2fa/disable", map[string]string{"code": code})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "two_factor_required_by_project", response["code"])
+}
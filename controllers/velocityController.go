@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"kanban/apperror"
+	"kanban/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectVelocity menghitung velocity sprint completed dalam project (per tipe estimasi),
+// rata-rata bergulir, standar deviasi dan perkiraan jumlah sprint untuk sisa pekerjaan
+func GetProjectVelocity(c *gin.Context) {
+	var query models.VelocityQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Window == 0 {
+		query.Window = models.DefaultVelocityWindow
+	}
+
+	var project models.Project
+	if err := db(c).Scopes(tenantProjects(c)).First(&project, c.Param("id")).Error; err != nil {
+		c.Error(apperror.NotFoundOr(err, apperror.CodeProjectNotFound))
+		return
+	}
+
+	var sprints []models.Sprint
+	if err := db(c).Where("project_id = ? AND status = ?", project.ID, models.SprintStatusCompleted).
+		Preload("Snapshot").Preload("Tasks").Find(&sprints).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	// Sisa pekerjaan: task belum selesai di backlog dan di sprint yang belum completed.
+	// Task di sprint dengan tipe estimasi lain tidak dihitung karena satuannya berbeda
+	// dengan velocity yang dipakai forecast.
+	var remaining float64
+	if err := db(c).Model(&models.Task{}).
+		Where("project_id = ? AND status <> ?", project.ID, models.TaskStatusDone).
+		Where("sprint_id IS NULL OR sprint_id IN (?)",
+			db(c).Model(&models.Sprint{}).Select("id").
+				Where("project_id = ? AND status <> ? AND estimation_type = ?",
+					project.ID, models.SprintStatusCompleted, models.LatestEstimationType(sprints))).
+		Select("COALESCE(SUM(estimation), 0)").Scan(&remaining).Error; err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.BuildVelocityReport(project.ID, sprints, query.Window, remaining)})
+}
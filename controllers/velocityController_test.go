@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"kanban/config"
+	"kanban/middlewares"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func setupVelocityTestDB() {
+	godotenv.Load("../.env")
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+
+	testDBName := os.Getenv("TEST_DB_NAME")
+	if testDBName == "" {
+		testDBName = "kanban_test"
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, testDBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect test database:", err)
+	}
+
+	db.AutoMigrate(&models.Project{}, &models.Sprint{}, &models.SprintSnapshot{}, &models.Task{}, &models.Organization{}, &models.OrganizationMember{})
+
+	config.DB = db
+}
+
+func teardownVelocityTestDB() {
+	if config.DB != nil {
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		config.DB.Exec("TRUNCATE TABLE tasks")
+		config.DB.Exec("TRUNCATE TABLE sprint_snapshots")
+		config.DB.Exec("TRUNCATE TABLE sprints")
+		config.DB.Exec("TRUNCATE TABLE projects")
+		config.DB.Exec("TRUNCATE TABLE organization_members")
+		config.DB.Exec("TRUNCATE TABLE organizations")
+		config.DB.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		sqlDB, _ := config.DB.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func createCompletedSprint(projectID uint, name string, completedAt time.Time, completed float64) {
+	sprint := models.Sprint{
+		Name:           name,
+		ProjectID:      projectID,
+		EstimationType: "story_point",
+		StartDate:      completedAt.AddDate(0, 0, -14),
+		EndDate:        completedAt,
+		Status:         "completed",
+		CompletedAt:    &completedAt,
+	}
+	config.DB.Create(&sprint)
+	config.DB.Create(&models.SprintSnapshot{
+		SprintID:            sprint.ID,
+		EstimationType:      sprint.EstimationType,
+		TotalEstimation:     completed,
+		CompletedEstimation: completed,
+		CompletedAt:         completedAt,
+	})
+}
+
+func TestGetProjectVelocity(t *testing.T) {
+	setupVelocityTestDB()
+	defer teardownVelocityTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	now := time.Now()
+	createCompletedSprint(project.ID, "Sprint 1", now.AddDate(0, 0, -28), 10)
+	createCompletedSprint(project.ID, "Sprint 2", now.AddDate(0, 0, -14), 20)
+	createCompletedSprint(project.ID, "Sprint 3", now, 30)
+	config.DB.Create(&models.Task{Title: "Backlog", Status: "todo", ProjectID: project.ID, Estimation: 40})
+	config.DB.Create(&models.Task{Title: "Done", Status: "done", ProjectID: project.ID, Estimation: 99})
+	// Sisa pekerjaan di sprint berestimasi jam tidak ikut forecast story point
+	hourSprint := models.Sprint{Name: "Hours", ProjectID: project.ID, EstimationType: "hour", Status: "planned", StartDate: now, EndDate: now.AddDate(0, 0, 14)}
+	config.DB.Create(&hourSprint)
+	config.DB.Create(&models.Task{Title: "Hours", Status: "todo", SprintID: &hourSprint.ID, Estimation: 100})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/projects/:id/velocity", GetProjectVelocity)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/velocity", project.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response struct {
+		Data models.VelocityReport `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &response)
+	report := response.Data
+
+	if assert.Len(t, report.Series, 1) {
+		series := report.Series[0]
+		assert.Equal(t, "story_point", series.EstimationType)
+		if assert.Len(t, series.Sprints, 3) {
+			assert.Equal(t, "Sprint 1", series.Sprints[0].Name)
+			assert.Equal(t, 15.0, series.Sprints[1].RollingAverage)
+		}
+		assert.Equal(t, 20.0, series.Average)
+		assert.InDelta(t, 8.165, series.StdDev, 0.001)
+	}
+	if assert.NotNil(t, report.Forecast) {
+		assert.Equal(t, "story_point", report.Forecast.EstimationType)
+		assert.Equal(t, 40.0, report.Forecast.RemainingEstimation)
+		assert.Equal(t, 2, *report.Forecast.ExpectedSprints)
+		assert.Equal(t, 2, *report.Forecast.OptimisticSprints)
+		assert.Equal(t, 4, *report.Forecast.PessimisticSprints)
+	}
+}
+
+func TestGetProjectVelocityInvalidWindow(t *testing.T) {
+	setupVelocityTestDB()
+	defer teardownVelocityTestDB()
+
+	org := seedTenant()
+	project := models.Project{Name: "Test Project", OrganizationID: &org.ID}
+	config.DB.Create(&project)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler(), asTenant(org.ID))
+	router.GET("/projects/:id/velocity", GetProjectVelocity)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/velocity?window=0", project.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	// window=0 dianggap tidak diisi dan memakai default
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/projects/%d/velocity?window=abc", project.ID), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// asyncSendTimeout membatasi lama satu job dari Background
+const asyncSendTimeout = 30 * time.Second
+
+// Message adalah email teks sederhana
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email. Implementasi dipilih lewat MAILER saat Setup.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	mu      sync.RWMutex
+	current Mailer = Console{}
+	pending sync.WaitGroup
+)
+
+// Setup memilih mailer sesuai MAILER: "console" (default, tulis ke log tanpa token),
+// "file" (simpan ke MAILER_FILE_DIR) atau "smtp" (konfigurasi SMTP_*)
+func Setup() error {
+	var m Mailer
+	switch strings.ToLower(os.Getenv("MAILER")) {
+	case "", "console":
+		m = Console{}
+	case "file":
+		dir := os.Getenv("MAILER_FILE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		m = File{Dir: dir}
+	case "smtp":
+		m = SMTP{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}
+	default:
+		return fmt.Errorf("MAILER tidak dikenal: %s", os.Getenv("MAILER"))
+	}
+	Set(m)
+	return nil
+}
+
+// Set mengganti mailer yang dipakai Send, misalnya dengan Memory di test
+func Set(m Mailer) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = m
+}
+
+// Send mengirim email memakai mailer yang sedang aktif
+func Send(ctx context.Context, msg Message) error {
+	mu.RLock()
+	m := current
+	mu.RUnlock()
+	return m.Send(ctx, msg)
+}
+
+// Background menjalankan job yang berakhir dengan pengiriman email, misalnya membuat token
+// lalu mengirim link-nya, di luar request sehingga waktu response tidak bergantung pada job
+// tersebut. Job tetap berjalan walaupun request sudah selesai, dibatasi asyncSendTimeout,
+// ditunggu oleh Wait, dan kegagalannya hanya dicatat di log dengan nama job.
+func Background(ctx context.Context, name string, job func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		ctx, cancel := context.WithTimeout(ctx, asyncSendTimeout)
+		defer cancel()
+		if err := job(ctx); err != nil {
+			slog.ErrorContext(ctx, "Gagal mengirim email", "job", name, "error", err)
+		}
+	}()
+}
+
+// Wait menunggu semua job dari Background selesai dikirim atau ctx berakhir
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Console menulis email ke log, untuk development. Nilai token di link (reset password,
+// verifikasi email, undangan) disensor karena log biasanya dibaca lebih banyak orang
+// daripada penerima email; pakai File untuk mendapatkan link lengkap.
+type Console struct{}
+
+func (Console) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "Email (console mailer)", "to", msg.To, "subject", msg.Subject, "body", redactTokens(msg.Body))
+	return nil
+}
+
+var tokenParam = regexp.MustCompile(`([?&]token=)[^&\s]+`)
+
+// redactTokens mengganti nilai parameter token di semua URL pada body
+func redactTokens(body string) string {
+	return tokenParam.ReplaceAllString(body, "${1}REDACTED")
+}
+
+// File menyimpan setiap email sebagai file .eml di Dir, untuk development
+type File struct {
+	Dir string
+}
+
+func (f File) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(f.Dir, 0o750); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(format("", msg)), 0o600)
+}
+
+// SMTP mengirim email lewat server SMTP dengan autentikasi PLAIN jika Username diisi
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s SMTP) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, s.From, []string{msg.To}, []byte(format(s.From, msg)))
+}
+
+// Memory menyimpan email di memori, untuk test
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (m *Memory) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages mengembalikan salinan email yang sudah dikirim
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+func format(from string, msg Message) string {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", header(from))
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", header(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", header(msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
+
+// header membuang CR/LF agar nilai header tidak bisa menyisipkan header lain
+func header(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
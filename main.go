@@ -1,10 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"kanban/config"
+	"kanban/controllers"
+	"kanban/logger"
+	"kanban/mailer"
+	"kanban/middlewares"
+	"kanban/oidc"
 	"kanban/routes"
+	"kanban/tracing"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	_ "kanban/docs"
 )
@@ -24,11 +39,88 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
-	r := gin.Default()
+	config.LoadEnv()
+	logger.Setup()
+
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		slog.Error("Gagal menyiapkan tracing", "error", err)
+		os.Exit(1)
+	}
+
+	if err := mailer.Setup(); err != nil {
+		slog.Error("Gagal menyiapkan mailer", "error", err)
+		os.Exit(1)
+	}
+
+	if err := oidc.Setup(); err != nil {
+		slog.Error("Gagal menyiapkan provider OIDC", "error", err)
+		os.Exit(1)
+	}
 
 	config.ConnectDB()
 
+	r := gin.New()
+	// Header X-Forwarded-For hanya dipercaya dari proxy yang terdaftar; default tidak ada,
+	// sehingga ClientIP (dipakai throttle login per IP) tidak bisa dipalsukan client
+	if err := r.SetTrustedProxies(config.GetEnvList("TRUSTED_PROXIES")); err != nil {
+		slog.Error("TRUSTED_PROXIES tidak valid", "error", err)
+		os.Exit(1)
+	}
+	if tracing.Enabled() {
+		r.Use(otelgin.Middleware(tracing.ServiceName))
+	}
+	r.Use(middlewares.RequestID(), middlewares.Logger(), middlewares.Recovery(), middlewares.ErrorHandler())
+
 	routes.RegisterRoutes(r)
 
-	r.Run(":8080")
-}
\ No newline at end of file
+	srv := &http.Server{
+		Addr:              ":" + config.GetEnv("PORT", "8080"),
+		Handler:           r,
+		ReadTimeout:       config.GetEnvDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+		ReadHeaderTimeout: config.GetEnvDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      config.GetEnvDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       config.GetEnvDuration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Hapus permanen isi tempat sampah yang melewati masa retensi
+	go controllers.RunTrashPurge(ctx)
+	// Hapus catatan login gagal yang sudah kedaluwarsa
+	go controllers.RunLoginThrottlePurge(ctx)
+
+	go func() {
+		slog.Info("Server berjalan", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Gagal menjalankan server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Menerima sinyal shutdown, menunggu request yang sedang berjalan")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.GetEnvDuration("SHUTDOWN_TIMEOUT", 20*time.Second))
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server shutdown tidak bersih", "error", err)
+	}
+	if err := mailer.Wait(shutdownCtx); err != nil {
+		slog.Error("Email yang belum terkirim dibatalkan", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Gagal mem-flush span tracing", "error", err)
+	}
+	if err := config.CloseDB(); err != nil {
+		slog.Error("Gagal menutup koneksi database", "error", err)
+	}
+	slog.Info("Server berhenti")
+}
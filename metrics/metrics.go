@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kanban"
+
+var (
+	// HTTPRequestsTotal menghitung request HTTP per route, method dan status code
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah request HTTP yang diproses.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration mencatat latensi request HTTP per route
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latensi request HTTP dalam detik.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueryDuration mencatat durasi query GORM per operasi dan tabel
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durasi query database dalam detik.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// DBQueryErrorsTotal menghitung query GORM yang gagal
+	DBQueryErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Jumlah query database yang gagal.",
+	}, []string{"operation", "table"})
+
+	// TasksCreatedTotal menghitung task yang dibuat
+	TasksCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_created_total",
+		Help:      "Jumlah task yang dibuat.",
+	})
+
+	// TaskStatusTransitionsTotal menghitung perpindahan status task
+	TaskStatusTransitionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_status_transitions_total",
+		Help:      "Jumlah perpindahan status task.",
+	}, []string{"from", "to"})
+
+	// SprintsCompletedTotal menghitung sprint yang diselesaikan
+	SprintsCompletedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sprints_completed_total",
+		Help:      "Jumlah sprint yang diselesaikan.",
+	})
+
+	// LoginLockoutsTotal menghitung akun yang dikunci karena terlalu banyak login gagal
+	LoginLockoutsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_lockouts_total",
+		Help:      "Jumlah lockout akun karena login gagal berulang.",
+	})
+)
+
+// RegisterDBStats mengekspos statistik pool koneksi database (open, in use, idle, wait)
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	err := prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		return nil
+	}
+	return err
+}
+
+// Handler mengembalikan handler HTTP untuk endpoint /metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"kanban/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin membatasi route untuk administrator sistem (User.IsAdmin).
+// Harus dipasang setelah AuthMiddleware, yang mengisi "is_admin" dari database.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("is_admin") {
+			c.Error(apperror.Forbidden(apperror.CodeForbidden))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
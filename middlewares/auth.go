@@ -1,32 +1,155 @@
 package middlewares
 
 import (
+	"errors"
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/models"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
-	var jwtKey = []byte("secret_key")
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+			c.Error(apperror.Unauthorized(apperror.CodeMissingToken))
 			c.Abort()
 			return
 		}
 
+		if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, tokenString)
+			return
+		}
+
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			return jwtKey, nil
 		})
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("username", claims["username"])
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		if err != nil || !token.Valid {
+			c.Error(&apperror.Error{Status: http.StatusUnauthorized, Code: apperror.CodeInvalidToken, Cause: err})
+			c.Abort()
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		// Token dengan claim "typ" (misalnya challenge 2FA) bukan token akses
+		if _, typed := claims["typ"]; !ok || typed {
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
+			c.Abort()
+			return
+		}
+		// Token lama tanpa claim "uid" tidak lagi diterima
+		userID, ok := claims["uid"].(float64)
+		if !ok || userID <= 0 {
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
 			c.Abort()
+			return
+		}
+		version := 0
+		if ver, ok := claims["ver"].(float64); ok {
+			version = int(ver)
+		}
+
+		// Token dicabut jika user sudah dihapus atau TokenVersion-nya sudah dinaikkan (misalnya setelah reset password)
+		var user models.User
+		if err := config.DB.WithContext(c.Request.Context()).Select("id", "username", "token_version", "is_admin", "disabled_at", "totp_enabled").
+			First(&user, uint(userID)).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
+			} else {
+				c.Error(apperror.Internal(err))
+			}
+			c.Abort()
+			return
+		}
+		if user.TokenVersion != version {
+			c.Error(apperror.Unauthorized(apperror.CodeSessionRevoked))
+			c.Abort()
+			return
+		}
+		if user.DisabledAt != nil {
+			c.Error(apperror.Forbidden(apperror.CodeAccountDisabled))
+			c.Abort()
+			return
 		}
+
+		c.Set("username", user.Username)
+		c.Set("user_id", user.ID)
+		c.Set("is_admin", user.IsAdmin)
+		c.Set("two_factor_enabled", user.TOTPEnabled)
+		c.Next()
+	}
+}
+
+// lastUsedInterval membatasi update last_used_at agar tidak ada write ke database di setiap request
+const lastUsedInterval = time.Minute
+
+// authenticatePersonalAccessToken memvalidasi token akses personal beserta scope-nya
+// untuk route yang diminta. Token ini tidak terikat TokenVersion; token dicabut lewat
+// DELETE /me/tokens/:id, saat reset password, atau saat ganti password dengan revoke_tokens.
+func authenticatePersonalAccessToken(c *gin.Context, raw string) {
+	db := config.DB.WithContext(c.Request.Context())
+
+	var pat models.PersonalAccessToken
+	if err := db.Where("token_hash = ?", models.HashToken(raw)).First(&pat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
+		} else {
+			c.Error(apperror.Internal(err))
+		}
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if !pat.Active(now) {
+		c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
+		c.Abort()
+		return
+	}
+
+	var user models.User
+	if err := db.Select("id", "username", "is_admin", "disabled_at", "totp_enabled").First(&user, pat.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
+		} else {
+			c.Error(apperror.Internal(err))
+		}
+		c.Abort()
+		return
 	}
+
+	if user.DisabledAt != nil {
+		c.Error(apperror.Forbidden(apperror.CodeAccountDisabled))
+		c.Abort()
+		return
+	}
+
+	if !pat.Allows(c.Request.Method, c.FullPath()) {
+		c.Error(apperror.Forbidden(apperror.CodeInsufficientScope).
+			WithDetails(map[string]interface{}{"scopes": pat.Scopes}))
+		c.Abort()
+		return
+	}
+
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) >= lastUsedInterval {
+		if err := db.Model(&pat).UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": c.ClientIP()}).Error; err != nil {
+			slog.WarnContext(c.Request.Context(), "Gagal mencatat pemakaian token akses", "token_id", pat.ID, "error", err)
+		}
+	}
+
+	c.Set("username", user.Username)
+	c.Set("user_id", user.ID)
+	c.Set("is_admin", user.IsAdmin)
+	c.Set("two_factor_enabled", user.TOTPEnabled)
+	c.Set("token_scopes", pat.Scopes)
+	c.Next()
 }
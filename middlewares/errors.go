@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"kanban/apperror"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler merender error yang didaftarkan handler lewat c.Error menjadi
+// response problem+json. Handler cukup memanggil c.Error(err) lalu return.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperror.From(c.Errors.Last().Err)
+		if appErr.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "request failed", "code", appErr.Code, "error", appErr.Error())
+		}
+		apperror.Render(c, appErr)
+	}
+}
+
+// NoRoute merender 404 untuk path yang tidak terdaftar
+func NoRoute(c *gin.Context) {
+	apperror.Render(c, apperror.NotFound(apperror.CodeRouteNotFound))
+}
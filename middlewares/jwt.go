@@ -1,17 +1,73 @@
 package middlewares
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateJWT(username string) (string, error) {
-	var jwtKey = []byte("secret_key")
+var jwtKey = []byte("secret_key")
+
+// challengeType menandai token sementara antara langkah password dan langkah 2FA saat login.
+// AuthMiddleware menolak token apa pun yang memiliki claim "typ".
+const challengeType = "2fa_challenge"
+
+// ChallengeTTL adalah masa berlaku challenge token 2FA
+const ChallengeTTL = 5 * time.Minute
+
+// GenerateJWT membuat token untuk user. User dicari lewat claim "uid" karena username bisa
+// diganti dan dipakai ulang user lain; "username" hanya informasi. tokenVersion disimpan di
+// claim "ver" dan harus sama dengan User.TokenVersion saat token dipakai, sehingga menaikkan
+// versi mencabut semua token lama.
+func GenerateJWT(userID uint, username string, tokenVersion int) (string, error) {
 	claims := jwt.MapClaims{
+		"uid":      userID,
 		"username": username,
+		"ver":      tokenVersion,
 		"exp":      time.Now().Add(time.Hour * 24).Unix(), // 1 hari
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtKey)
 }
+
+// GenerateChallengeToken membuat token berumur pendek yang hanya bisa ditukar dengan
+// JWT penuh lewat POST /login/2fa setelah kode 2FA diverifikasi. Seperti GenerateJWT,
+// user dicari lewat claim "uid"; "username" hanya dipakai untuk throttle login.
+func GenerateChallengeToken(userID uint, username string, tokenVersion int) (string, error) {
+	claims := jwt.MapClaims{
+		"uid":      userID,
+		"username": username,
+		"ver":      tokenVersion,
+		"typ":      challengeType,
+		"exp":      time.Now().Add(ChallengeTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtKey)
+}
+
+// ParseChallengeToken memvalidasi challenge token dan mengembalikan ID user, username
+// serta versinya
+func ParseChallengeToken(tokenString string) (uint, string, int, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return jwtKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != challengeType {
+		return 0, "", 0, errors.New("bukan challenge token 2FA")
+	}
+	userID, ok := claims["uid"].(float64)
+	if !ok || userID <= 0 {
+		return 0, "", 0, errors.New("challenge token tanpa claim uid")
+	}
+	username, _ := claims["username"].(string)
+	version := 0
+	if ver, ok := claims["ver"].(float64); ok {
+		version = int(ver)
+	}
+	return uint(userID), username, version, nil
+}
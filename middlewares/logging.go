@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"kanban/apperror"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger mencatat setiap request sebagai log JSON terstruktur
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		ctx := c.Request.Context()
+		switch {
+		case status >= http.StatusInternalServerError:
+			slog.ErrorContext(ctx, "request", attrs...)
+		case status >= http.StatusBadRequest:
+			slog.WarnContext(ctx, "request", attrs...)
+		default:
+			slog.InfoContext(ctx, "request", attrs...)
+		}
+	}
+}
+
+// Recovery menangkap panic, mencatatnya lewat slog dan mengembalikan 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered", "panic", recovered, "path", c.Request.URL.Path)
+		apperror.Render(c, apperror.New(http.StatusInternalServerError, apperror.CodeInternal))
+	})
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"kanban/apperror"
+	"kanban/metrics"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics mencatat jumlah dan latensi request per route untuk Prometheus
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Pakai template route (/tasks/:id) agar label tidak meledak per ID
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+
+		metrics.HTTPRequestsTotal.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RequireMetricsToken membatasi endpoint metrics untuk scraper yang mengirim token
+// (METRICS_TOKEN) sebagai bearer token. Token kosong berarti endpoint selalu ditolak.
+func RequireMetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if given == "" {
+			c.Error(apperror.Unauthorized(apperror.CodeMissingToken))
+			c.Abort()
+			return
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.Error(apperror.Unauthorized(apperror.CodeInvalidToken))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
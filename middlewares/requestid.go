@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"kanban/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader adalah header yang membawa request ID dari client/proxy dan kembali di response
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID memakai X-Request-ID dari client jika ada, atau membuat yang baru,
+// lalu menyimpannya di gin context dan request context untuk logging
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", requestID))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
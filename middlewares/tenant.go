@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"kanban/apperror"
+	"kanban/config"
+	"kanban/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantScope mengisi "organization_ids" dengan organisasi tempat user yang sedang login
+// menjadi anggota. Controller memakainya untuk membatasi query project, sprint dan task.
+// Harus dipasang setelah AuthMiddleware.
+func TenantScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgIDs := []uint{}
+		if err := config.DB.WithContext(c.Request.Context()).Model(&models.OrganizationMember{}).
+			Where("user_id = ?", c.GetUint("user_id")).Pluck("organization_id", &orgIDs).Error; err != nil {
+			c.Error(apperror.Internal(err))
+			c.Abort()
+			return
+		}
+		c.Set("organization_ids", orgIDs)
+		c.Next()
+	}
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// CumulativeFlowDay adalah jumlah task per status pada akhir satu hari
+type CumulativeFlowDay struct {
+	Date   string         `json:"date"`
+	Counts map[string]int `json:"counts"`
+}
+
+// HistogramBucket adalah jumlah task dengan durasi di rentang [MinDays, MaxDays)
+type HistogramBucket struct {
+	MinDays float64  `json:"min_days"`
+	MaxDays *float64 `json:"max_days"` // nil berarti tanpa batas atas
+	Count   int      `json:"count"`
+}
+
+// FlowTimeStats adalah statistik lead time atau cycle time dalam jam
+type FlowTimeStats struct {
+	Count       int               `json:"count"`
+	MeanHours   float64           `json:"mean_hours"`
+	MedianHours float64           `json:"median_hours"`
+	P85Hours    float64           `json:"p85_hours"`
+	Histogram   []HistogramBucket `json:"histogram"`
+}
+
+// FlowReport menggabungkan cumulative flow serta statistik lead time dan cycle time
+type FlowReport struct {
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	CumulativeFlow []CumulativeFlowDay `json:"cumulative_flow"`
+	LeadTime       FlowTimeStats       `json:"lead_time"`
+	CycleTime      FlowTimeStats       `json:"cycle_time"`
+}
+
+// histogramBounds adalah batas bucket histogram dalam hari
+var histogramBounds = []float64{1, 2, 3, 5, 8, 13}
+
+// sortedTransitions mengembalikan transisi task urut berdasarkan waktu
+func (t *Task) sortedTransitions() []TaskStatusTransition {
+	transitions := make([]TaskStatusTransition, len(t.Transitions))
+	copy(transitions, t.Transitions)
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].ChangedAt.Before(transitions[j].ChangedAt)
+	})
+	return transitions
+}
+
+// statusTimeline menelusuri status satu task maju sepanjang waktu. Transisi hanya
+// diurutkan sekali, sehingga chart harian cukup berjalan satu kali per task.
+type statusTimeline struct {
+	task        *Task
+	transitions []TaskStatusTransition
+	next        int
+	status      string
+	found       bool
+}
+
+func newStatusTimeline(t *Task) *statusTimeline {
+	return &statusTimeline{task: t, transitions: t.sortedTransitions()}
+}
+
+// at mengembalikan status task pada waktu tertentu. Waktu harus tidak mundur dari
+// pemanggilan sebelumnya.
+func (tl *statusTimeline) at(at time.Time) (string, bool) {
+	if len(tl.transitions) == 0 {
+		if tl.task.CreatedAt.After(at) {
+			return "", false
+		}
+		return tl.task.Status, true
+	}
+	for tl.next < len(tl.transitions) && !tl.transitions[tl.next].ChangedAt.After(at) {
+		tl.status, tl.found = tl.transitions[tl.next].ToStatus, true
+		tl.next++
+	}
+	return tl.status, tl.found
+}
+
+// newStatusTimelines membuat statusTimeline untuk setiap task
+func newStatusTimelines(tasks []Task) []*statusTimeline {
+	timelines := make([]*statusTimeline, len(tasks))
+	for i := range tasks {
+		timelines[i] = newStatusTimeline(&tasks[i])
+	}
+	return timelines
+}
+
+// StatusAt mengembalikan status task pada waktu tertentu. Task tanpa riwayat transisi
+// (dibuat sebelum transisi dicatat) dianggap berada di status saat ini sejak dibuat.
+func (t *Task) StatusAt(at time.Time) (string, bool) {
+	return newStatusTimeline(t).at(at)
+}
+
+// LeadTime adalah durasi dari task dibuat sampai terakhir kali masuk done
+func (t *Task) LeadTime() (time.Duration, bool) {
+	return leadTime(t, t.sortedTransitions())
+}
+
+// CycleTime adalah durasi dari pertama kali task dikerjakan (in_progress) sampai terakhir kali masuk done
+func (t *Task) CycleTime() (time.Duration, bool) {
+	return cycleTime(t, t.sortedTransitions())
+}
+
+// leadTime dan cycleTime memakai transisi yang sudah diurutkan oleh sortedTransitions
+func leadTime(t *Task, sorted []TaskStatusTransition) (time.Duration, bool) {
+	done, ok := lastDoneAt(t, sorted)
+	if !ok {
+		return 0, false
+	}
+	return done.Sub(t.CreatedAt), true
+}
+
+func cycleTime(t *Task, sorted []TaskStatusTransition) (time.Duration, bool) {
+	done, ok := lastDoneAt(t, sorted)
+	if !ok {
+		return 0, false
+	}
+	for _, transition := range sorted {
+		if transition.ToStatus == TaskStatusInProgress {
+			return done.Sub(transition.ChangedAt), true
+		}
+	}
+	return 0, false
+}
+
+func lastDoneAt(t *Task, sorted []TaskStatusTransition) (time.Time, bool) {
+	if t.Status != TaskStatusDone {
+		return time.Time{}, false
+	}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].ToStatus == TaskStatusDone {
+			return sorted[i].ChangedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// BuildFlowReport menghitung cumulative flow harian dari from sampai to (inklusif)
+// dan statistik lead/cycle time. Transitions tiap task harus sudah di-load.
+func BuildFlowReport(tasks []Task, from, to time.Time) FlowReport {
+	from = startOfDay(from)
+	to = startOfDay(to)
+
+	report := FlowReport{
+		From:           from.Format(time.DateOnly),
+		To:             to.Format(time.DateOnly),
+		CumulativeFlow: []CumulativeFlowDay{},
+	}
+
+	timelines := newStatusTimelines(tasks)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		counts := make(map[string]int, len(TaskStatuses))
+		for _, status := range TaskStatuses {
+			counts[status] = 0
+		}
+		for _, timeline := range timelines {
+			if status, ok := timeline.at(endOfDay); ok {
+				counts[status]++
+			}
+		}
+		report.CumulativeFlow = append(report.CumulativeFlow, CumulativeFlowDay{Date: day.Format(time.DateOnly), Counts: counts})
+	}
+
+	var leadTimes, cycleTimes []time.Duration
+	for _, timeline := range timelines {
+		if lead, ok := leadTime(timeline.task, timeline.transitions); ok {
+			leadTimes = append(leadTimes, lead)
+		}
+		if cycle, ok := cycleTime(timeline.task, timeline.transitions); ok {
+			cycleTimes = append(cycleTimes, cycle)
+		}
+	}
+	report.LeadTime = BuildFlowTimeStats(leadTimes)
+	report.CycleTime = BuildFlowTimeStats(cycleTimes)
+
+	return report
+}
+
+// BuildFlowTimeStats menghitung mean, median, persentil 85 dan histogram (per hari) dari durasi
+func BuildFlowTimeStats(durations []time.Duration) FlowTimeStats {
+	stats := FlowTimeStats{Count: len(durations), Histogram: make([]HistogramBucket, 0, len(histogramBounds)+1)}
+
+	lower := 0.0
+	for i := range histogramBounds {
+		stats.Histogram = append(stats.Histogram, HistogramBucket{MinDays: lower, MaxDays: &histogramBounds[i]})
+		lower = histogramBounds[i]
+	}
+	stats.Histogram = append(stats.Histogram, HistogramBucket{MinDays: lower})
+
+	if len(durations) == 0 {
+		return stats
+	}
+
+	hours := make([]float64, len(durations))
+	for i, d := range durations {
+		hours[i] = d.Hours()
+		days := d.Hours() / 24
+		bucket := sort.SearchFloat64s(histogramBounds, days)
+		if bucket < len(histogramBounds) && histogramBounds[bucket] == days {
+			bucket++ // batas atas bucket bersifat eksklusif
+		}
+		stats.Histogram[bucket].Count++
+	}
+	sort.Float64s(hours)
+
+	stats.MeanHours = mean(hours)
+	stats.MedianHours = percentile(hours, 50)
+	stats.P85Hours = percentile(hours, 85)
+	return stats
+}
+
+// percentile memakai metode nearest-rank pada data yang sudah terurut
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
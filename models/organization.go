@@ -0,0 +1,77 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+)
+
+// Role anggota organisasi
+const (
+	OrgRoleAdmin  = "admin"  // mengelola anggota dan undangan organisasi
+	OrgRoleMember = "member" // melihat dan mengerjakan project organisasi
+)
+
+// OrgRoles adalah daftar role anggota organisasi yang valid
+var OrgRoles = []string{OrgRoleAdmin, OrgRoleMember}
+
+// Organization adalah tenant yang memiliki project. User hanya melihat project milik
+// organisasi tempat dia menjadi anggota.
+type Organization struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationWithRole adalah organisasi beserta role user yang sedang login di dalamnya
+type OrganizationWithRole struct {
+	Organization
+	Role string `json:"role"`
+}
+
+// OrganizationMember adalah keanggotaan user di organisasi beserta role-nya
+type OrganizationMember struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_org_member"`
+	UserID         uint      `json:"user_id" gorm:"uniqueIndex:idx_org_member;index"`
+	Role           string    `json:"role" gorm:"size:16"`
+	CreatedAt      time.Time `json:"created_at"`
+	User           User      `json:"user"`
+}
+
+// OrganizationInvitation adalah undangan bergabung ke organisasi yang dikirim ke email.
+// Seperti token reset password, hanya hash token yang disimpan.
+type OrganizationInvitation struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"index"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role" gorm:"size:16"`
+	TokenHash      string     `json:"-" gorm:"size:64;uniqueIndex"`
+	InvitedBy      uint       `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// NewOrganizationInvitation membuat undangan dengan token acak dan mengembalikan token mentahnya
+func NewOrganizationInvitation(orgID uint, email, role string, invitedBy uint, ttl time.Duration) (OrganizationInvitation, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return OrganizationInvitation{}, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return OrganizationInvitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           role,
+		TokenHash:      HashToken(token),
+		InvitedBy:      invitedBy,
+		ExpiresAt:      time.Now().Add(ttl),
+	}, token, nil
+}
+
+// Usable mengecek apakah undangan belum diterima dan belum kedaluwarsa
+func (i *OrganizationInvitation) Usable(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// PasswordResetToken adalah token sekali pakai untuk reset password. Hanya hash SHA-256
+// token yang disimpan; token aslinya hanya dikirim ke email user.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewPasswordResetToken membuat token acak untuk user dan mengembalikan token mentahnya
+func NewPasswordResetToken(userID uint, ttl time.Duration) (PasswordResetToken, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return PasswordResetToken{}, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return PasswordResetToken{
+		UserID:    userID,
+		TokenHash: HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}, token, nil
+}
+
+// HashToken menghitung hash yang disimpan di database untuk token mentah
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Usable mengecek apakah token belum dipakai dan belum kedaluwarsa
+func (t *PasswordResetToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PersonalAccessTokenPrefix menandai token akses personal sehingga AuthMiddleware
+// bisa membedakannya dari JWT tanpa mencoba mem-parse-nya
+const PersonalAccessTokenPrefix = "kpat_"
+
+// Scope token akses personal
+const (
+	ScopeRead       = "read"        // semua request GET di luar /admin
+	ScopeTasksWrite = "tasks:write" // read + mengubah task
+	ScopeAdmin      = "admin"       // akses penuh seperti sesi login, termasuk /admin bagi administrator
+)
+
+// TokenScopes adalah semua scope yang bisa dipilih saat membuat token
+var TokenScopes = []string{ScopeRead, ScopeTasksWrite, ScopeAdmin}
+
+// PersonalAccessToken adalah token berumur panjang untuk script dan CI. Seperti token
+// reset password, hanya hash SHA-256-nya yang disimpan.
+type PersonalAccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	Name       string     `json:"name" gorm:"size:100"`
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex"`
+	Hint       string     `json:"hint" gorm:"size:16"` // beberapa karakter awal token untuk dikenali di daftar
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:45"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewPersonalAccessToken membuat token acak dan mengembalikan token mentahnya
+func NewPersonalAccessToken(userID uint, name string, scopes []string, expiresAt *time.Time) (PersonalAccessToken, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return PersonalAccessToken{}, "", err
+	}
+	token := PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: HashToken(token),
+		Hint:      token[:len(PersonalAccessTokenPrefix)+4],
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, token, nil
+}
+
+// Active mengecek apakah token belum dicabut dan belum kedaluwarsa
+func (t *PersonalAccessToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// Allows mengecek apakah scope token mengizinkan request dengan method dan route tertentu
+func (t *PersonalAccessToken) Allows(method, route string) bool {
+	if slices.Contains(t.Scopes, ScopeAdmin) {
+		return true
+	}
+	// Route /admin hanya bisa dipakai token dengan scope admin
+	if route == "/admin" || strings.HasPrefix(route, "/admin/") {
+		return false
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return slices.Contains(t.Scopes, ScopeRead) || slices.Contains(t.Scopes, ScopeTasksWrite)
+	}
+	return slices.Contains(t.Scopes, ScopeTasksWrite) && (route == "/tasks" || strings.HasPrefix(route, "/tasks/"))
+}
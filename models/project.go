@@ -1,11 +1,35 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Role participant project
+const (
+	ProjectRoleMaintainer = "maintainer" // ikut mengelola undangan project bersama pemilik
+	ProjectRoleMember     = "member"
+)
+
+// ProjectRoles adalah daftar role participant project yang valid
+var ProjectRoles = []string{ProjectRoleMaintainer, ProjectRoleMember}
 
 type Project struct {
 	gorm.Model
-	Name             string `json:"name"`
-	Description      string `json:"description"`
-	UserParticipants []User  `gorm:"many2many:project_users;"`
-	
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	RequireTwoFactor bool       `json:"require_two_factor" gorm:"not null;default:false"` // semua participant wajib mengaktifkan 2FA
+	OrganizationID   *uint      `json:"organization_id" gorm:"index"`                     // tenant pemilik project
+	OwnerID          *uint      `json:"owner_id" gorm:"index"`                            // pembuat project; dipindah lewat PUT /admin/projects/:id/owner
+	ArchivedAt       *time.Time `json:"archived_at" gorm:"index"`                         // project arsip bersifat read-only
+	UserParticipants []User     `gorm:"many2many:project_users;"`
+}
+
+// ProjectUser adalah baris join table project_users. Participant yang ditambahkan lewat
+// association UserParticipants memakai role default member.
+type ProjectUser struct {
+	ProjectID uint   `json:"project_id" gorm:"primaryKey"`
+	UserID    uint   `json:"user_id" gorm:"primaryKey"`
+	Role      string `json:"role" gorm:"size:16;not null;default:member"`
 }
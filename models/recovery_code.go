@@ -0,0 +1,53 @@
+package models
+
+import (
+	"crypto/rand"
+	"strings"
+	"time"
+)
+
+// RecoveryCodeCount adalah jumlah recovery code yang dibuat setiap kali 2FA diaktifkan atau dibuat ulang
+const RecoveryCodeCount = 10
+
+// recoveryAlphabet berisi 32 karakter tanpa yang mudah tertukar (0/O, 1/I),
+// sehingga tiap byte acak bisa dipetakan tanpa bias dengan mask 5 bit
+const recoveryAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// RecoveryCode adalah kode sekali pakai pengganti TOTP jika perangkat authenticator hilang.
+// Seperti PasswordResetToken, hanya hash-nya yang disimpan.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	CodeHash  string     `json:"-" gorm:"size:64;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewRecoveryCodes membuat RecoveryCodeCount kode baru untuk user dan mengembalikan kode mentahnya
+// dengan format XXXXX-XXXXX
+func NewRecoveryCodes(userID uint) ([]RecoveryCode, []string, error) {
+	records := make([]RecoveryCode, RecoveryCodeCount)
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		var b strings.Builder
+		for j, v := range raw {
+			if j == 5 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryAlphabet[v&31])
+		}
+		codes[i] = b.String()
+		records[i] = RecoveryCode{UserID: userID, CodeHash: HashRecoveryCode(codes[i])}
+	}
+	return records, codes, nil
+}
+
+// HashRecoveryCode menormalkan kode (huruf besar, tanpa spasi/tanda hubung) lalu menghitung hash-nya
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return HashToken(normalized)
+}
@@ -0,0 +1,232 @@
+package models
+
+import "time"
+
+// Request DTO untuk setiap endpoint yang menerima body JSON.
+// Tag binding divalidasi sebelum handler menulis apa pun ke database;
+// tag custom (taskstatus, sprintstatus, estimationtype, maxrange) didaftarkan di package validation.
+// Keberadaan ID yang direferensikan (project, sprint, user) dicek handler dalam scope tenant,
+// sehingga ID milik organisasi lain tidak bisa dibedakan dari ID yang tidak ada.
+
+// RegisterRequest adalah body untuk POST /register
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"omitempty,email,max=255"`
+}
+
+// UpdateProfileRequest adalah body untuk PATCH /me; field yang tidak dikirim tidak diubah.
+// Email kosong menghapus email user.
+type UpdateProfileRequest struct {
+	Username    *string `json:"username" binding:"omitempty,min=3,max=50"`
+	Email       *string `json:"email" binding:"omitempty,email,max=255"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,url,max=2048"`
+}
+
+// ChangePasswordRequest adalah body untuk PUT /me/password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,nefield=CurrentPassword"`
+	RevokeTokens    bool   `json:"revoke_tokens"` // ikut mencabut semua token akses personal
+}
+
+// ForgotPasswordRequest adalah body untuk POST /password/forgot
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+// ResetPasswordRequest adalah body untuk POST /password/reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// LoginTwoFactorRequest adalah body untuk POST /login/2fa. Code berisi kode TOTP atau recovery code.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeRequest adalah body endpoint /me/2fa yang membutuhkan kode TOTP
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ProjectTwoFactorRequest adalah body untuk PUT /projects/:id/two-factor
+type ProjectTwoFactorRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+// CreatePersonalAccessTokenRequest adalah body untuk POST /me/tokens.
+// ExpiresInDays kosong memakai PAT_DEFAULT_EXPIRY_DAYS.
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,unique,dive,tokenscope"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// AdminUserQuery adalah query string untuk GET /admin/users. Q mencari di username,
+// email dan display name; default 50 user per halaman.
+type AdminUserQuery struct {
+	Q        string `json:"q" form:"q" binding:"max=100"`
+	Disabled *bool  `json:"disabled" form:"disabled"`
+	Page     int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PerPage  int    `json:"per_page" form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// SetAdminRequest adalah body untuk PUT /admin/users/:id/admin
+type SetAdminRequest struct {
+	Admin *bool `json:"admin" binding:"required"`
+}
+
+// TransferProjectOwnerRequest adalah body untuk PUT /admin/projects/:id/owner
+type TransferProjectOwnerRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// CreateOrganizationRequest adalah body untuk POST /organizations
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// OrganizationMemberRequest adalah body untuk PUT /organizations/:id/members/:user_id
+type OrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,orgrole"`
+}
+
+// CreateOrganizationInvitationRequest adalah body untuk POST /organizations/:id/invitations.
+// Role kosong berarti member.
+type CreateOrganizationInvitationRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Role  string `json:"role" binding:"omitempty,orgrole"`
+}
+
+// VerifyEmailRequest adalah body untuk POST /email/verify
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AcceptInvitationRequest adalah body untuk POST /invitations/accept dan
+// POST /project-invitations/accept
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// LoginRequest adalah body untuk POST /login
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// CreateProjectRequest adalah body untuk POST /projects
+type CreateProjectRequest struct {
+	Name           string `json:"name" binding:"required,max=255"`
+	Description    string `json:"description" binding:"required"`
+	Hours          int    `json:"hours" binding:"gte=0"`
+	StoryPoints    int    `json:"story_points" binding:"gte=0"`
+	ParticipantIDs []uint `json:"participant_ids" binding:"omitempty,unique,dive,gt=0"`
+	OrganizationID uint   `json:"organization_id"` // wajib jika user tergabung di lebih dari satu organisasi
+}
+
+// AddParticipantRequest adalah body untuk POST /projects/:id/participants
+type AddParticipantRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// CreateProjectInvitationRequest adalah body untuk POST /projects/:id/invitations. Email
+// kosong membuat link undangan yang bisa dibagikan; undangan email selalu sekali pakai.
+// Role kosong berarti member, MaxUses kosong berarti 1 dan 0 berarti tanpa batas.
+// ExpiresInDays kosong memakai PROJECT_INVITATION_TTL.
+type CreateProjectInvitationRequest struct {
+	Email         string `json:"email" binding:"omitempty,email,max=255"`
+	Role          string `json:"role" binding:"omitempty,projectrole"`
+	MaxUses       *int   `json:"max_uses" binding:"omitempty,min=0,max=1000"`
+	ExpiresInDays *int   `json:"expires_in_days" binding:"omitempty,min=1,max=90"`
+}
+
+// CreateTaskRequest adalah body untuk POST /tasks. Salah satu dari project_id
+// atau sprint_id wajib diisi; tanpa sprint_id task masuk ke backlog project.
+type CreateTaskRequest struct {
+	Title      string  `json:"title" binding:"required,max=255"`
+	Status     string  `json:"status" binding:"required,taskstatus"`
+	ProjectID  uint    `json:"project_id" binding:"omitempty"`
+	SprintID   uint    `json:"sprint_id" binding:"omitempty"`
+	AssignTo   uint    `json:"assign_to" binding:"omitempty"`
+	Estimation float64 `json:"estimation" binding:"gte=0"`
+}
+
+// UpdateTaskStatusRequest adalah body untuk PUT /tasks/:id
+type UpdateTaskStatusRequest struct {
+	Status string `json:"status" binding:"required,taskstatus"`
+}
+
+// UpdateTaskEstimationRequest adalah body untuk PUT /tasks/:id/estimation
+type UpdateTaskEstimationRequest struct {
+	Estimation float64 `json:"estimation" binding:"gte=0"`
+}
+
+// AssignTaskRequest adalah body untuk PUT /tasks/:id/assign; assign_to 0 berarti unassign
+type AssignTaskRequest struct {
+	AssignTo uint `json:"assign_to" binding:"omitempty"`
+}
+
+// BacklogTasksRequest adalah body untuk PUT /projects/:id/backlog (urutan baru)
+// dan POST /sprints/:id/tasks (task backlog yang ditarik ke sprint)
+type BacklogTasksRequest struct {
+	TaskIDs []uint `json:"task_ids" binding:"required,min=1,unique,dive,gt=0"`
+}
+
+// SprintCapacityEntry adalah kapasitas satu participant dalam satuan estimasi sprint
+type SprintCapacityEntry struct {
+	UserID   uint    `json:"user_id" binding:"required"`
+	Capacity float64 `json:"capacity" binding:"gte=0"`
+	DaysOff  int     `json:"days_off" binding:"gte=0"`
+}
+
+// SetSprintCapacityRequest adalah body untuk PUT /sprints/:id/capacity
+type SetSprintCapacityRequest struct {
+	Capacities []SprintCapacityEntry `json:"capacities" binding:"required,min=1,dive"`
+}
+
+// VelocityQuery adalah query string untuk GET /projects/:id/velocity
+type VelocityQuery struct {
+	Window int `json:"window" form:"window" binding:"omitempty,min=1,max=20"`
+}
+
+// ArchiveQuery adalah query string untuk daftar project dan sprint. Item yang diarsipkan
+// hanya ikut jika include_archived=true.
+type ArchiveQuery struct {
+	IncludeArchived bool `json:"include_archived" form:"include_archived"`
+}
+
+// FlowQuery adalah query string untuk GET /projects/:id/flow; default 30 hari terakhir
+type FlowQuery struct {
+	From time.Time `json:"from" form:"from" time_format:"2006-01-02"`
+	To   time.Time `json:"to" form:"to" time_format:"2006-01-02" binding:"omitempty,gtefield=From"`
+}
+
+// CreateSprintRequest adalah body untuk POST /sprints. Panjang sprint maksimal MaxSprintDays hari.
+type CreateSprintRequest struct {
+	Name                string    `json:"name" binding:"required,max=255"`
+	ProjectID           uint      `json:"project_id" binding:"required"`
+	Goal                string    `json:"goal"`
+	EstimationType      string    `json:"estimation_type" binding:"required,estimationtype"`
+	TotalEstimation     float64   `json:"total_estimation" binding:"gte=0"`
+	RemainingEstimation float64   `json:"remaining_estimation" binding:"gte=0"`
+	StartDate           time.Time `json:"start_date" binding:"required"`
+	EndDate             time.Time `json:"end_date" binding:"required,gtfield=StartDate,maxrange=366"`
+	Status              string    `json:"status" binding:"omitempty,oneof=planned"` // sprint baru selalu planned
+}
+
+// UpdateSprintStatusRequest adalah body untuk PUT /sprints/:id/status
+type UpdateSprintStatusRequest struct {
+	Status string `json:"status" binding:"required,sprintstatus"`
+}
+
+// CompleteSprintRequest adalah body opsional untuk POST /sprints/:id/complete.
+// Task yang belum selesai dipindah ke backlog (default) atau ke sprint next_sprint_id.
+type CompleteSprintRequest struct {
+	CarryOverTo  string `json:"carry_over_to" binding:"omitempty,oneof=sprint backlog"`
+	NextSprintID uint   `json:"next_sprint_id" binding:"omitempty"`
+}
@@ -1,20 +1,93 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status sprint
+const (
+	SprintStatusPlanned   = "planned"
+	SprintStatusActive    = "active"
+	SprintStatusCompleted = "completed"
+)
+
+// SprintStatuses adalah daftar status sprint yang valid
+var SprintStatuses = []string{SprintStatusPlanned, SprintStatusActive, SprintStatusCompleted}
+
+// Tipe estimasi sprint
+const (
+	EstimationTypeHour       = "hour"
+	EstimationTypeStoryPoint = "story_point"
+)
+
+// EstimationTypes adalah daftar tipe estimasi yang valid
+var EstimationTypes = []string{EstimationTypeHour, EstimationTypeStoryPoint}
+
+// sprintTransitions adalah alur status sprint: planned → active → completed
+var sprintTransitions = map[string][]string{
+	SprintStatusPlanned: {SprintStatusActive},
+	SprintStatusActive:  {SprintStatusCompleted},
+}
 
 type Sprint struct {
-	ID                  uint      `json:"id" gorm:"primaryKey"`
-	ProjectID           uint      `json:"project_id"`
-	Name                string    `json:"name"`
-	Goal                string    `json:"goal"`
-	EstimationType      string    `json:"estimation_type"` // "hour" atau "story_point"
-	TotalEstimation     float64   `json:"total_estimation"`
-	RemainingEstimation float64   `json:"remaining_estimation"`
-	StartDate           time.Time `json:"start_date"`
-	EndDate             time.Time `json:"end_date"`
-	Status              string    `json:"status"` // planned, active, completed
-	Project             Project   `json:"project" gorm:"foreignKey:ProjectID"`
-	Tasks               []Task    `json:"tasks" gorm:"foreignKey:SprintID"`
+	ID                  uint            `json:"id" gorm:"primaryKey"`
+	ProjectID           uint            `json:"project_id"`
+	Name                string          `json:"name"`
+	Goal                string          `json:"goal"`
+	EstimationType      string          `json:"estimation_type"` // "hour" atau "story_point"
+	TotalEstimation     float64         `json:"total_estimation"`
+	RemainingEstimation float64         `json:"remaining_estimation"`
+	StartDate           time.Time       `json:"start_date"`
+	EndDate             time.Time       `json:"end_date"`
+	Status              string          `json:"status"` // planned, active, completed
+	StartedAt           *time.Time      `json:"started_at"`
+	CompletedAt         *time.Time      `json:"completed_at"`
+	ArchivedAt          *time.Time      `json:"archived_at" gorm:"index"` // sprint arsip bersifat read-only
+	DeletedAt           gorm.DeletedAt  `json:"deleted_at" gorm:"index"`  // soft delete; dihapus permanen setelah TRASH_RETENTION
+	Project             Project         `json:"project" gorm:"foreignKey:ProjectID"`
+	Tasks               []Task          `json:"tasks" gorm:"foreignKey:SprintID"`
+	Snapshot            *SprintSnapshot `json:"snapshot,omitempty" gorm:"foreignKey:SprintID"`
+}
+
+// AllowedTransitions mengembalikan status yang boleh dituju dari status sprint saat ini
+func (s *Sprint) AllowedTransitions() []string {
+	return sprintTransitions[s.Status]
+}
+
+// CanTransitionTo mengecek apakah sprint boleh berpindah ke status tujuan
+func (s *Sprint) CanTransitionTo(status string) bool {
+	return slices.Contains(sprintTransitions[s.Status], status)
+}
+
+// MaxSprintDays adalah panjang maksimal sprint, sekaligus batas rentang chart harian sprint.
+// Nilainya harus sama dengan tag maxrange pada CreateSprintRequest.EndDate.
+const MaxSprintDays = 366
+
+// ReportingPeriod mengembalikan rentang waktu sprint untuk chart harian: sejak sprint
+// dimulai sampai selesai, atau sampai hari ini jika sprint masih berjalan
+func (s *Sprint) ReportingPeriod() (from, to time.Time) {
+	from = s.StartDate
+	if s.StartedAt != nil {
+		from = *s.StartedAt
+	}
+	to = s.EndDate
+	if s.CompletedAt != nil {
+		to = *s.CompletedAt
+	} else if now := time.Now(); now.Before(to) {
+		to = now
+	}
+	if to.Before(from) {
+		to = from
+	}
+	// Chart harian dibatasi MaxSprintDays agar tanggal yang tidak wajar tidak membuat
+	// jutaan titik data
+	if limit := from.AddDate(0, 0, MaxSprintDays); to.After(limit) {
+		to = limit
+	}
+	return from, to
 }
 
 // CalculateTotalEstimation menghitung total estimasi dari semua tasks dalam sprint
@@ -31,7 +104,7 @@ func (s *Sprint) CalculateRemainingEstimation() float64 {
 	var remaining float64
 	for _, task := range s.Tasks {
 		// Hanya hitung task yang belum selesai (status bukan "done")
-		if task.Status != "done" {
+		if task.Status != TaskStatusDone {
 			remaining += task.Estimation
 		}
 	}
@@ -42,7 +115,7 @@ func (s *Sprint) CalculateRemainingEstimation() float64 {
 func (s *Sprint) CalculateCompletedEstimation() float64 {
 	var completed float64
 	for _, task := range s.Tasks {
-		if task.Status == "done" {
+		if task.Status == TaskStatusDone {
 			completed += task.Estimation
 		}
 	}
@@ -62,14 +135,14 @@ func (s *Sprint) GetProgressPercentage() float64 {
 // GetTaskStatusBreakdown menghitung breakdown task berdasarkan status
 func (s *Sprint) GetTaskStatusBreakdown() map[string]int {
 	breakdown := map[string]int{
-		"todo":        0,
-		"in_progress": 0,
-		"done":        0,
+		TaskStatusTodo:       0,
+		TaskStatusInProgress: 0,
+		TaskStatusDone:       0,
 	}
-	
+
 	for _, task := range s.Tasks {
 		breakdown[task.Status]++
 	}
-	
+
 	return breakdown
 }
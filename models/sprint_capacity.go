@@ -0,0 +1,165 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// SprintCapacity adalah kapasitas satu participant project dalam sebuah sprint.
+// Capacity memakai satuan Sprint.EstimationType (jam atau story point) untuk seluruh
+// hari kerja sprint; DaysOff mengurangi kapasitas secara proporsional.
+type SprintCapacity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SprintID  uint      `json:"sprint_id" gorm:"uniqueIndex:idx_sprint_capacity_user"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_sprint_capacity_user"`
+	Capacity  float64   `json:"capacity"`
+	DaysOff   int       `json:"days_off"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EffectiveCapacity menghitung kapasitas setelah dikurangi hari libur
+func (c *SprintCapacity) EffectiveCapacity(workingDays int) float64 {
+	if workingDays <= 0 {
+		return 0
+	}
+	available := workingDays - c.DaysOff
+	if available <= 0 {
+		return 0
+	}
+	return c.Capacity * float64(available) / float64(workingDays)
+}
+
+// WorkingDays menghitung jumlah hari kerja (Senin-Jumat) dari StartDate sampai EndDate
+func (s *Sprint) WorkingDays() int {
+	start := time.Date(s.StartDate.Year(), s.StartDate.Month(), s.StartDate.Day(), 0, 0, 0, 0, s.StartDate.Location())
+	end := time.Date(s.EndDate.Year(), s.EndDate.Month(), s.EndDate.Day(), 0, 0, 0, 0, s.StartDate.Location())
+
+	days := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// Kode warning planning sprint
+const (
+	PlanningWarningMemberOverCommitted = "member_over_committed"
+	PlanningWarningSprintOverCommitted = "sprint_over_committed"
+	PlanningWarningMissingCapacity     = "missing_capacity"
+	PlanningWarningUnassignedWork      = "unassigned_work"
+)
+
+// PlanningWarning menjelaskan potensi masalah pada rencana sprint
+type PlanningWarning struct {
+	Code      string  `json:"code"`
+	UserID    *uint   `json:"user_id,omitempty"`
+	Committed float64 `json:"committed"`
+	Capacity  float64 `json:"capacity"`
+}
+
+// MemberPlan membandingkan estimasi task yang di-assign ke satu user dengan kapasitasnya
+type MemberPlan struct {
+	UserID            uint    `json:"user_id"`
+	Username          string  `json:"username"`
+	Capacity          float64 `json:"capacity"`
+	DaysOff           int     `json:"days_off"`
+	EffectiveCapacity float64 `json:"effective_capacity"`
+	Committed         float64 `json:"committed"`
+	Remaining         float64 `json:"remaining"`
+	Utilization       float64 `json:"utilization_percentage"`
+	OverCommitted     bool    `json:"over_committed"`
+}
+
+// SprintPlan adalah hasil perbandingan komitmen sprint terhadap kapasitas tim
+type SprintPlan struct {
+	SprintID           uint              `json:"sprint_id"`
+	EstimationType     string            `json:"estimation_type"`
+	WorkingDays        int               `json:"working_days"`
+	TotalCapacity      float64           `json:"total_capacity"`
+	TotalCommitted     float64           `json:"total_committed"`
+	UnassignedEstimate float64           `json:"unassigned_estimation"`
+	Utilization        float64           `json:"utilization_percentage"`
+	OverCommitted      bool              `json:"over_committed"`
+	Members            []MemberPlan      `json:"members"`
+	Warnings           []PlanningWarning `json:"warnings"`
+}
+
+// BuildSprintPlan menghitung rencana sprint dari tasks sprint (harus sudah di-load),
+// entri kapasitas dan username participant
+func BuildSprintPlan(sprint *Sprint, capacities []SprintCapacity, usernames map[uint]string) SprintPlan {
+	plan := SprintPlan{
+		SprintID:       sprint.ID,
+		EstimationType: sprint.EstimationType,
+		WorkingDays:    sprint.WorkingDays(),
+		Members:        []MemberPlan{},
+		Warnings:       []PlanningWarning{},
+	}
+
+	members := map[uint]*MemberPlan{}
+	member := func(userID uint) *MemberPlan {
+		if m, ok := members[userID]; ok {
+			return m
+		}
+		m := &MemberPlan{UserID: userID, Username: usernames[userID]}
+		members[userID] = m
+		return m
+	}
+
+	hasCapacity := map[uint]bool{}
+	for _, capacity := range capacities {
+		m := member(capacity.UserID)
+		m.Capacity = capacity.Capacity
+		m.DaysOff = capacity.DaysOff
+		m.EffectiveCapacity = capacity.EffectiveCapacity(plan.WorkingDays)
+		hasCapacity[capacity.UserID] = true
+		plan.TotalCapacity += m.EffectiveCapacity
+	}
+
+	for _, task := range sprint.Tasks {
+		plan.TotalCommitted += task.Estimation
+		if task.AssignTo == nil {
+			plan.UnassignedEstimate += task.Estimation
+			continue
+		}
+		member(*task.AssignTo).Committed += task.Estimation
+	}
+
+	for _, m := range members {
+		m.Remaining = m.EffectiveCapacity - m.Committed
+		m.Utilization = percentage(m.Committed, m.EffectiveCapacity)
+		m.OverCommitted = m.Committed > m.EffectiveCapacity
+		plan.Members = append(plan.Members, *m)
+	}
+	sort.Slice(plan.Members, func(i, j int) bool { return plan.Members[i].UserID < plan.Members[j].UserID })
+
+	for _, m := range plan.Members {
+		userID := m.UserID
+		switch {
+		case !hasCapacity[userID]:
+			plan.Warnings = append(plan.Warnings, PlanningWarning{Code: PlanningWarningMissingCapacity, UserID: &userID, Committed: m.Committed})
+		case m.OverCommitted:
+			plan.Warnings = append(plan.Warnings, PlanningWarning{Code: PlanningWarningMemberOverCommitted, UserID: &userID, Committed: m.Committed, Capacity: m.EffectiveCapacity})
+		}
+	}
+
+	plan.Utilization = percentage(plan.TotalCommitted, plan.TotalCapacity)
+	plan.OverCommitted = plan.TotalCommitted > plan.TotalCapacity
+	if plan.OverCommitted {
+		plan.Warnings = append(plan.Warnings, PlanningWarning{Code: PlanningWarningSprintOverCommitted, Committed: plan.TotalCommitted, Capacity: plan.TotalCapacity})
+	}
+	if plan.UnassignedEstimate > 0 {
+		plan.Warnings = append(plan.Warnings, PlanningWarning{Code: PlanningWarningUnassignedWork, Committed: plan.UnassignedEstimate})
+	}
+
+	return plan
+}
+
+func percentage(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole * 100
+}
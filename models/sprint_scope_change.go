@@ -0,0 +1,117 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Jenis perubahan scope sprint
+const (
+	ScopeChangeAdded       = "added"
+	ScopeChangeRemoved     = "removed"
+	ScopeChangeReEstimated = "re_estimated"
+)
+
+// SprintScopeChange mencatat task yang ditambah, dihapus atau diestimasi ulang
+// setelah sprint aktif, sehingga pertumbuhan scope terlihat di burn-up
+type SprintScopeChange struct {
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	SprintID           uint      `json:"sprint_id" gorm:"index"`
+	TaskID             uint      `json:"task_id" gorm:"index"`
+	TaskTitle          string    `json:"task_title"`
+	Type               string    `json:"type"`
+	PreviousEstimation float64   `json:"previous_estimation"`
+	NewEstimation      float64   `json:"new_estimation"`
+	Delta              float64   `json:"delta"`
+	ChangedAt          time.Time `json:"changed_at" gorm:"index"`
+}
+
+// NewSprintScopeChange membuat catatan perubahan scope untuk task dalam sprint
+func NewSprintScopeChange(sprintID uint, task *Task, changeType string, previous, next float64, at time.Time) SprintScopeChange {
+	return SprintScopeChange{
+		SprintID:           sprintID,
+		TaskID:             task.ID,
+		TaskTitle:          task.Title,
+		Type:               changeType,
+		PreviousEstimation: previous,
+		NewEstimation:      next,
+		Delta:              next - previous,
+		ChangedAt:          at,
+	}
+}
+
+// ScopeChangeSummary merangkum perubahan scope sprint untuk analytics
+type ScopeChangeSummary struct {
+	AddedCount       int     `json:"added_count"`
+	RemovedCount     int     `json:"removed_count"`
+	ReEstimatedCount int     `json:"re_estimated_count"`
+	NetChange        float64 `json:"net_change"`
+}
+
+// SummarizeScopeChanges menghitung jumlah perubahan per jenis dan total perubahan estimasi
+func SummarizeScopeChanges(changes []SprintScopeChange) ScopeChangeSummary {
+	var summary ScopeChangeSummary
+	for _, change := range changes {
+		switch change.Type {
+		case ScopeChangeAdded:
+			summary.AddedCount++
+		case ScopeChangeRemoved:
+			summary.RemovedCount++
+		case ScopeChangeReEstimated:
+			summary.ReEstimatedCount++
+		}
+		summary.NetChange += change.Delta
+	}
+	return summary
+}
+
+// BurnUpPoint adalah total scope dan estimasi selesai pada akhir satu hari sprint
+type BurnUpPoint struct {
+	Day        int     `json:"day"`
+	Date       string  `json:"date"`
+	TotalScope float64 `json:"total_scope"`
+	Completed  float64 `json:"completed"`
+	Remaining  float64 `json:"remaining"`
+}
+
+// BuildBurnUp menghitung seri burn-up harian. Total scope tiap hari direkonstruksi dari
+// scope akhir dikurangi perubahan scope setelah hari itu; estimasi selesai dihitung dari
+// riwayat status task. Tasks (beserta Transitions) dan Snapshot harus sudah di-load.
+func BuildBurnUp(sprint *Sprint, changes []SprintScopeChange) []BurnUpPoint {
+	finalScope := sprint.CalculateTotalEstimation()
+	if sprint.Snapshot != nil {
+		finalScope = sprint.Snapshot.TotalEstimation
+	}
+
+	from, to := sprint.ReportingPeriod()
+	from, to = startOfDay(from), startOfDay(to)
+
+	points := []BurnUpPoint{}
+	timelines := newStatusTimelines(sprint.Tasks)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+		scope := finalScope
+		for _, change := range changes {
+			if change.ChangedAt.After(endOfDay) {
+				scope -= change.Delta
+			}
+		}
+
+		var completed float64
+		for _, timeline := range timelines {
+			if status, ok := timeline.at(endOfDay); ok && status == TaskStatusDone {
+				completed += timeline.task.Estimation
+			}
+		}
+
+		points = append(points, BurnUpPoint{
+			Day:        len(points) + 1,
+			Date:       day.Format(time.DateOnly),
+			TotalScope: scope,
+			Completed:  completed,
+			Remaining:  math.Max(scope-completed, 0),
+		})
+	}
+	return points
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// SprintSnapshot menyimpan metrik final sprint saat diselesaikan,
+// sehingga laporan tidak berubah meskipun task diedit setelahnya
+type SprintSnapshot struct {
+	ID                    uint      `json:"id" gorm:"primaryKey"`
+	SprintID              uint      `json:"sprint_id" gorm:"uniqueIndex"`
+	EstimationType        string    `json:"estimation_type"`
+	TotalEstimation       float64   `json:"total_estimation"`
+	CompletedEstimation   float64   `json:"completed_estimation"`
+	RemainingEstimation   float64   `json:"remaining_estimation"`
+	ProgressPercentage    float64   `json:"progress_percentage"`
+	TaskCount             int       `json:"task_count"`
+	TodoCount             int       `json:"todo_count"`
+	InProgressCount       int       `json:"in_progress_count"`
+	DoneCount             int       `json:"done_count"`
+	CarriedOverCount      int       `json:"carried_over_count"`
+	CarriedOverEstimation float64   `json:"carried_over_estimation"`
+	CompletedAt           time.Time `json:"completed_at"`
+}
+
+// NewSprintSnapshot menghitung metrik final dari sprint beserta tasks-nya
+func NewSprintSnapshot(sprint *Sprint, completedAt time.Time) SprintSnapshot {
+	breakdown := sprint.GetTaskStatusBreakdown()
+	return SprintSnapshot{
+		SprintID:            sprint.ID,
+		EstimationType:      sprint.EstimationType,
+		TotalEstimation:     sprint.CalculateTotalEstimation(),
+		CompletedEstimation: sprint.CalculateCompletedEstimation(),
+		RemainingEstimation: sprint.CalculateRemainingEstimation(),
+		ProgressPercentage:  sprint.GetProgressPercentage(),
+		TaskCount:           len(sprint.Tasks),
+		TodoCount:           breakdown[TaskStatusTodo],
+		InProgressCount:     breakdown[TaskStatusInProgress],
+		DoneCount:           breakdown[TaskStatusDone],
+		CompletedAt:         completedAt,
+	}
+}
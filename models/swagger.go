@@ -49,7 +49,23 @@ type MessageResponse struct {
 	Message string `json:"message" example:"Success"`
 }
 
-// ErrorResponse represents an error response
+// FieldErrorResponse represents a single invalid field in an error response
+type FieldErrorResponse struct {
+	Field   string `json:"field" example:"title"`
+	Code    string `json:"code" example:"required"`
+	Param   string `json:"param,omitempty" example:""`
+	Message string `json:"message" example:"This field is required"`
+}
+
+// ErrorResponse represents an RFC 7807 problem+json error response
 type ErrorResponse struct {
-	Error string `json:"error" example:"Something went wrong"`
-}
\ No newline at end of file
+	Type      string                 `json:"type" example:"/errors/task_not_found"`
+	Title     string                 `json:"title" example:"Not Found"`
+	Status    int                    `json:"status" example:"404"`
+	Detail    string                 `json:"detail" example:"Task not found"`
+	Instance  string                 `json:"instance,omitempty" example:"/tasks/42"`
+	Code      string                 `json:"code" example:"task_not_found"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Errors    []FieldErrorResponse   `json:"errors,omitempty"`
+	RequestID string                 `json:"request_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
@@ -2,14 +2,36 @@ package models
 
 import "gorm.io/gorm"
 
+// Status task
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+)
+
+// TaskStatuses adalah daftar status task yang valid, sesuai urutan alur kerja
+var TaskStatuses = []string{TaskStatusTodo, TaskStatusInProgress, TaskStatusDone}
+
 type Task struct {
 	gorm.Model
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Status      string  `json:"status"`
-	SprintID    uint   `json:"sprint_id"`
-	AssignTo    *uint   `json:"assign_to"`
-	Estimation  float64 `json:"estimation"`
-	Sprint      Sprint  `json:"sprint"`
-	User        User    `json:"user" gorm:"foreignKey:AssignTo"`
+	Title          string                 `json:"title"`
+	Description    string                 `json:"description"`
+	Status         string                 `json:"status"`
+	ProjectID      uint                   `json:"project_id" gorm:"index"`
+	SprintID       *uint                  `json:"sprint_id"` // nil berarti task ada di backlog project
+	AssignTo       *uint                  `json:"assign_to"`
+	Estimation     float64                `json:"estimation"`
+	CarryOverCount int                    `json:"carry_over_count"`
+	BacklogRank    int                    `json:"backlog_rank" gorm:"index"` // urutan di backlog, makin kecil makin prioritas
+	Sprint         *Sprint                `json:"sprint,omitempty"`
+	Transitions    []TaskStatusTransition `json:"status_transitions,omitempty" gorm:"foreignKey:TaskID"`
+	User           User                   `json:"user" gorm:"foreignKey:AssignTo"`
+}
+
+// BeforeCreate mengisi ProjectID dari sprint jika belum diisi
+func (t *Task) BeforeCreate(tx *gorm.DB) error {
+	if t.ProjectID != 0 || t.SprintID == nil {
+		return nil
+	}
+	return tx.Model(&Sprint{}).Where("id = ?", *t.SprintID).Select("project_id").Scan(&t.ProjectID).Error
 }
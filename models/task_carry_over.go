@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Tujuan carry over task yang belum selesai
+const (
+	CarryOverToSprint  = "sprint"
+	CarryOverToBacklog = "backlog"
+)
+
+// TaskCarryOver mencatat task yang belum selesai dan dipindahkan saat sprint diselesaikan.
+// ToSprintID nil berarti task dikembalikan ke backlog project.
+type TaskCarryOver struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TaskID       uint      `json:"task_id" gorm:"index"`
+	FromSprintID uint      `json:"from_sprint_id" gorm:"index"`
+	ToSprintID   *uint     `json:"to_sprint_id" gorm:"index"`
+	Estimation   float64   `json:"estimation"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CarryOverSummary merangkum carry over masuk dan keluar sebuah sprint untuk analytics
+type CarryOverSummary struct {
+	CarriedInCount        int     `json:"carried_in_count"`
+	CarriedInEstimation   float64 `json:"carried_in_estimation"`
+	CarriedOutCount       int     `json:"carried_out_count"`
+	CarriedOutEstimation  float64 `json:"carried_out_estimation"`
+	RepeatedlyCarriedOver int     `json:"repeatedly_carried_over"` // task di sprint ini yang sudah dipindah lebih dari sekali
+}
+
+// SummarizeCarryOvers menghitung ringkasan carry over untuk sprint tertentu
+func SummarizeCarryOvers(sprint *Sprint, records []TaskCarryOver) CarryOverSummary {
+	var summary CarryOverSummary
+	for _, record := range records {
+		if record.FromSprintID == sprint.ID {
+			summary.CarriedOutCount++
+			summary.CarriedOutEstimation += record.Estimation
+		}
+		if record.ToSprintID != nil && *record.ToSprintID == sprint.ID {
+			summary.CarriedInCount++
+			summary.CarriedInEstimation += record.Estimation
+		}
+	}
+	for _, task := range sprint.Tasks {
+		if task.CarryOverCount > 1 {
+			summary.RepeatedlyCarriedOver++
+		}
+	}
+	return summary
+}
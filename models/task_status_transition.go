@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TaskStatusTransition mencatat setiap perubahan status task, termasuk status awal
+// saat task dibuat (FromStatus kosong). Dipakai untuk cumulative flow dan lead/cycle time.
+type TaskStatusTransition struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TaskID     uint      `json:"task_id" gorm:"index"`
+	ProjectID  uint      `json:"project_id" gorm:"index"`
+	SprintID   *uint     `json:"sprint_id" gorm:"index"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedAt  time.Time `json:"changed_at" gorm:"index"`
+}
+
+// NewTaskStatusTransition membuat catatan perpindahan status task dari status from ke status task saat ini
+func NewTaskStatusTransition(task *Task, from string, at time.Time) TaskStatusTransition {
+	return TaskStatusTransition{
+		TaskID:     task.ID,
+		ProjectID:  task.ProjectID,
+		SprintID:   task.SprintID,
+		FromStatus: from,
+		ToStatus:   task.Status,
+		ChangedAt:  at,
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+// ProjectTrash adalah isi tempat sampah sebuah project: task dan sprint yang sudah
+// di-soft-delete dan masih bisa dipulihkan sampai masa retensi habis
+type ProjectTrash struct {
+	ProjectID     uint     `json:"project_id"`
+	RetentionDays int      `json:"retention_days"` // 0 berarti tidak pernah dihapus permanen
+	Tasks         []Task   `json:"tasks"`
+	Sprints       []Sprint `json:"sprints"`
+}
+
+// TrashPurgeResult adalah jumlah record yang dihapus permanen oleh job purge
+type TrashPurgeResult struct {
+	Projects int64 `json:"projects"`
+	Sprints  int64 `json:"sprints"`
+	Tasks    int64 `json:"tasks"`
+}
@@ -1,12 +1,25 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	ID       uint      `json:"id"`
-	Username string    `json:"username" gorm:"unique"`
-	Password string    `json:"password"`
-	Email    string    `json:"email" gorm:"unique"`
-	Projects []Project `json:"projects,omitempty" gorm:"many2many:project_users;"`
+	ID              uint       `json:"id"`
+	Username        string     `json:"username" gorm:"unique"`
+	Password        string     `json:"-"`
+	Email           string     `json:"email" gorm:"unique;default:null"` // kosong disimpan sebagai NULL agar tidak bentrok dengan index unique
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`                // terisi setelah user membuktikan memiliki email, dikosongkan saat email diganti
+	DisplayName     string     `json:"display_name"`
+	AvatarURL       string     `json:"avatar_url"`
+	TokenVersion    int        `json:"-" gorm:"not null;default:0"` // dinaikkan untuk mencabut semua token JWT user
+	TOTPSecret      string     `json:"-"`                           // terisi sejak setup 2FA, aktif setelah diverifikasi
+	TOTPEnabled     bool       `json:"two_factor_enabled" gorm:"not null;default:false"`
+	TOTPLastStep    int64      `json:"-"`                                      // langkah TOTP terakhir yang dipakai, mencegah kode dipakai ulang
+	IsAdmin         bool       `json:"is_admin" gorm:"not null;default:false"` // administrator sistem, bisa memakai /admin
+	DisabledAt      *time.Time `json:"disabled_at"`                            // user yang dinonaktifkan tidak bisa login maupun memakai token
+	Projects        []Project  `json:"projects,omitempty" gorm:"many2many:project_users;"`
 }
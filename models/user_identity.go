@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// UserIdentity menautkan akun di identity provider OIDC (provider + subject) ke User
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index"`
+	Provider    string     `json:"provider" gorm:"size:64;uniqueIndex:idx_identity_subject"`
+	Subject     string     `json:"subject" gorm:"size:255;uniqueIndex:idx_identity_subject"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCLoginState menyimpan state, nonce dan code verifier PKCE di antara redirect ke
+// identity provider dan callback-nya. Seperti token reset password, state hanya disimpan
+// dalam bentuk hash dan hanya bisa dipakai sekali.
+type OIDCLoginState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"size:64;uniqueIndex"`
+	Provider     string    `json:"provider" gorm:"size:64"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	LinkUserID   *uint     `json:"link_user_id"` // terisi jika alur dimulai user yang sudah login untuk menautkan identity
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName mencegah GORM menamai tabel o_id_c_login_states
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// DefaultVelocityWindow adalah jumlah sprint terakhir yang dipakai untuk rata-rata bergulir
+const DefaultVelocityWindow = 3
+
+// VelocityPoint adalah estimasi yang diselesaikan dalam satu sprint yang sudah completed
+type VelocityPoint struct {
+	SprintID       uint       `json:"sprint_id"`
+	Name           string     `json:"name"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	Committed      float64    `json:"committed"`
+	Completed      float64    `json:"completed"`
+	RollingAverage float64    `json:"rolling_average"`
+}
+
+// VelocitySeries adalah velocity sprint dengan tipe estimasi yang sama.
+// Average, StdDev, Min dan Max dihitung dari sprint di dalam window terakhir.
+type VelocitySeries struct {
+	EstimationType string          `json:"estimation_type"`
+	Sprints        []VelocityPoint `json:"sprints"`
+	Average        float64         `json:"average"`
+	StdDev         float64         `json:"std_dev"`
+	Min            float64         `json:"min"`
+	Max            float64         `json:"max"`
+}
+
+// VelocityForecast memperkirakan berapa sprint lagi untuk menyelesaikan sisa pekerjaan.
+// Nilai sprint nil berarti tidak bisa diperkirakan (velocity nol).
+type VelocityForecast struct {
+	EstimationType      string  `json:"estimation_type"`
+	RemainingEstimation float64 `json:"remaining_estimation"`
+	AverageVelocity     float64 `json:"average_velocity"`
+	ExpectedSprints     *int    `json:"expected_sprints"`
+	OptimisticSprints   *int    `json:"optimistic_sprints"`
+	PessimisticSprints  *int    `json:"pessimistic_sprints"`
+}
+
+// VelocityReport adalah velocity lintas sprint dalam satu project
+type VelocityReport struct {
+	ProjectID uint              `json:"project_id"`
+	Window    int               `json:"window"`
+	Series    []VelocitySeries  `json:"series"`
+	Forecast  *VelocityForecast `json:"forecast"`
+}
+
+// CompletedVelocity mengembalikan estimasi selesai sprint, memakai snapshot final jika ada
+func (s *Sprint) CompletedVelocity() (committed, completed float64) {
+	if s.Snapshot != nil {
+		return s.Snapshot.TotalEstimation, s.Snapshot.CompletedEstimation
+	}
+	return s.CalculateTotalEstimation(), s.CalculateCompletedEstimation()
+}
+
+// BuildVelocityReport menghitung velocity dari sprint completed (Snapshot atau Tasks sudah di-load).
+// Forecast memakai tipe estimasi dari LatestEstimationType; remaining harus dihitung dengan
+// satuan yang sama.
+func BuildVelocityReport(projectID uint, sprints []Sprint, window int, remaining float64) VelocityReport {
+	if window <= 0 {
+		window = DefaultVelocityWindow
+	}
+
+	ordered := make([]Sprint, len(sprints))
+	copy(ordered, sprints)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return completedAt(&ordered[i]).Before(completedAt(&ordered[j]))
+	})
+
+	report := VelocityReport{ProjectID: projectID, Window: window, Series: []VelocitySeries{}}
+	index := map[string]int{}
+	for i := range ordered {
+		sprint := &ordered[i]
+		pos, ok := index[sprint.EstimationType]
+		if !ok {
+			pos = len(report.Series)
+			index[sprint.EstimationType] = pos
+			report.Series = append(report.Series, VelocitySeries{EstimationType: sprint.EstimationType, Sprints: []VelocityPoint{}})
+		}
+		committed, completed := sprint.CompletedVelocity()
+		report.Series[pos].Sprints = append(report.Series[pos].Sprints, VelocityPoint{
+			SprintID:    sprint.ID,
+			Name:        sprint.Name,
+			CompletedAt: sprint.CompletedAt,
+			Committed:   committed,
+			Completed:   completed,
+		})
+	}
+
+	for i := range report.Series {
+		series := &report.Series[i]
+		values := make([]float64, len(series.Sprints))
+		for j, point := range series.Sprints {
+			values[j] = point.Completed
+			series.Sprints[j].RollingAverage = mean(lastN(values[:j+1], window))
+		}
+		recent := lastN(values, window)
+		series.Average = mean(recent)
+		series.StdDev = stdDev(recent)
+		if len(recent) > 0 {
+			series.Min = slices.Min(recent)
+			series.Max = slices.Max(recent)
+		}
+	}
+
+	if latest := LatestEstimationType(sprints); latest != "" {
+		series := report.Series[index[latest]]
+		report.Forecast = &VelocityForecast{
+			EstimationType:      latest,
+			RemainingEstimation: remaining,
+			AverageVelocity:     series.Average,
+			ExpectedSprints:     sprintsNeeded(remaining, series.Average),
+			OptimisticSprints:   sprintsNeeded(remaining, series.Average+series.StdDev),
+			PessimisticSprints:  sprintsNeeded(remaining, series.Average-series.StdDev),
+		}
+	}
+
+	return report
+}
+
+// LatestEstimationType mengembalikan tipe estimasi sprint yang paling terakhir selesai, atau
+// string kosong jika belum ada sprint. Sisa backlog belum terikat ke sprint sehingga
+// diasumsikan memakai satuan yang sedang dipakai tim.
+func LatestEstimationType(sprints []Sprint) string {
+	var latest *Sprint
+	for i := range sprints {
+		if latest == nil || !completedAt(&sprints[i]).Before(completedAt(latest)) {
+			latest = &sprints[i]
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.EstimationType
+}
+
+func completedAt(sprint *Sprint) time.Time {
+	if sprint.CompletedAt != nil {
+		return *sprint.CompletedAt
+	}
+	return sprint.EndDate
+}
+
+func sprintsNeeded(remaining, velocity float64) *int {
+	if remaining <= 0 {
+		zero := 0
+		return &zero
+	}
+	if velocity <= 0 {
+		return nil
+	}
+	n := int(math.Ceil(remaining / velocity))
+	return &n
+}
+
+func lastN(values []float64, n int) []float64 {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev menghitung standar deviasi populasi
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	avg := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
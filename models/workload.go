@@ -0,0 +1,152 @@
+package models
+
+import "sort"
+
+// SprintTaskGroup adalah task milik satu user dalam satu sprint (atau backlog jika Sprint nil),
+// dikelompokkan per status
+type SprintTaskGroup struct {
+	ProjectID           uint              `json:"project_id"`
+	Sprint              *SprintSummary    `json:"sprint"`
+	TasksByStatus       map[string][]Task `json:"tasks_by_status"`
+	RemainingEstimation float64           `json:"remaining_estimation"`
+}
+
+// SprintSummary adalah ringkasan sprint tanpa tasks untuk response dashboard
+type SprintSummary struct {
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	EstimationType string `json:"estimation_type"`
+}
+
+// sprintGroupOrder menaruh sprint aktif paling atas, lalu planned, backlog dan completed
+var sprintGroupOrder = map[string]int{
+	SprintStatusActive:    0,
+	SprintStatusPlanned:   1,
+	"":                    2, // backlog
+	SprintStatusCompleted: 3,
+}
+
+// GroupTasksBySprint mengelompokkan task per sprint lalu per status. Sprint tiap task harus sudah di-load.
+func GroupTasksBySprint(tasks []Task) []SprintTaskGroup {
+	type key struct {
+		projectID uint
+		sprintID  uint
+	}
+
+	groups := []SprintTaskGroup{}
+	index := map[key]int{}
+	for _, task := range tasks {
+		k := key{projectID: task.ProjectID}
+		if task.SprintID != nil {
+			k.sprintID = *task.SprintID
+		}
+
+		pos, ok := index[k]
+		if !ok {
+			group := SprintTaskGroup{ProjectID: task.ProjectID, TasksByStatus: map[string][]Task{}}
+			for _, status := range TaskStatuses {
+				group.TasksByStatus[status] = []Task{}
+			}
+			if task.Sprint != nil {
+				group.Sprint = &SprintSummary{
+					ID:             task.Sprint.ID,
+					Name:           task.Sprint.Name,
+					Status:         task.Sprint.Status,
+					EstimationType: task.Sprint.EstimationType,
+				}
+			}
+			pos = len(groups)
+			index[k] = pos
+			groups = append(groups, group)
+		}
+
+		task.Sprint = nil
+		groups[pos].TasksByStatus[task.Status] = append(groups[pos].TasksByStatus[task.Status], task)
+		if task.Status != TaskStatusDone {
+			groups[pos].RemainingEstimation += task.Estimation
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		oi, oj := sprintGroupOrder[groups[i].sprintStatus()], sprintGroupOrder[groups[j].sprintStatus()]
+		if oi != oj {
+			return oi < oj
+		}
+		if groups[i].ProjectID != groups[j].ProjectID {
+			return groups[i].ProjectID < groups[j].ProjectID
+		}
+		return groups[i].sprintID() < groups[j].sprintID()
+	})
+	return groups
+}
+
+func (g *SprintTaskGroup) sprintStatus() string {
+	if g.Sprint == nil {
+		return ""
+	}
+	return g.Sprint.Status
+}
+
+func (g *SprintTaskGroup) sprintID() uint {
+	if g.Sprint == nil {
+		return 0
+	}
+	return g.Sprint.ID
+}
+
+// MemberWorkload adalah beban kerja terbuka (task belum done) satu user dalam project.
+// UserID nil berarti task yang belum di-assign.
+type MemberWorkload struct {
+	UserID              *uint   `json:"user_id"`
+	Username            string  `json:"username"`
+	Participant         bool    `json:"participant"`
+	OpenTaskCount       int     `json:"open_task_count"`
+	InProgressCount     int     `json:"in_progress_count"`
+	RemainingEstimation float64 `json:"remaining_estimation"`
+}
+
+// BuildProjectWorkload menghitung beban kerja per participant dari task project yang belum selesai.
+// Assignee yang sudah bukan participant tetap ditampilkan agar task-nya tidak hilang dari laporan.
+func BuildProjectWorkload(participants []User, assignees map[uint]string, openTasks []Task) []MemberWorkload {
+	workloads := []MemberWorkload{}
+	index := map[uint]int{}
+	for _, user := range participants {
+		id := user.ID
+		index[id] = len(workloads)
+		workloads = append(workloads, MemberWorkload{UserID: &id, Username: user.Username, Participant: true})
+	}
+
+	unassigned := MemberWorkload{}
+	for _, task := range openTasks {
+		if task.Status == TaskStatusDone {
+			continue
+		}
+
+		workload := &unassigned
+		if task.AssignTo != nil {
+			pos, ok := index[*task.AssignTo]
+			if !ok {
+				id := *task.AssignTo
+				pos = len(workloads)
+				index[id] = pos
+				workloads = append(workloads, MemberWorkload{UserID: &id, Username: assignees[id]})
+			}
+			workload = &workloads[pos]
+		}
+
+		workload.OpenTaskCount++
+		workload.RemainingEstimation += task.Estimation
+		if task.Status == TaskStatusInProgress {
+			workload.InProgressCount++
+		}
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		return workloads[i].RemainingEstimation > workloads[j].RemainingEstimation
+	})
+	if unassigned.OpenTaskCount > 0 {
+		workloads = append(workloads, unassigned)
+	}
+	return workloads
+}
@@ -0,0 +1,75 @@
+package oidc
+
+import (
+	"fmt"
+	"kanban/config"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]*Provider{}
+)
+
+// Setup membaca provider dari OIDC_PROVIDERS (nama dipisah koma) dan, untuk tiap nama,
+// OIDC_<NAMA>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL, _SCOPES,
+// _LINK_BY_EMAIL dan _AUTO_PROVISION
+func Setup() error {
+	configured := map[string]*Provider{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		issuer := os.Getenv(prefix + "ISSUER")
+		clientID := os.Getenv(prefix + "CLIENT_ID")
+		redirectURL := os.Getenv(prefix + "REDIRECT_URL")
+		if issuer == "" || clientID == "" || redirectURL == "" {
+			return fmt.Errorf("provider OIDC %s membutuhkan %sISSUER, %sCLIENT_ID dan %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+
+		provider := NewProvider(name, issuer, clientID, os.Getenv(prefix+"CLIENT_SECRET"), redirectURL,
+			strings.Fields(os.Getenv(prefix+"SCOPES")))
+		provider.LinkByEmail = config.GetEnvBool(prefix+"LINK_BY_EMAIL", false)
+		provider.AutoProvision = config.GetEnvBool(prefix+"AUTO_PROVISION", true)
+		configured[name] = provider
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	providers = configured
+	return nil
+}
+
+// Set mendaftarkan provider, misalnya provider yang mengarah ke mock IdP di test
+func Set(p *Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name] = p
+}
+
+// Get mengembalikan provider berdasarkan nama
+func Get(name string) (*Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if p, ok := providers[strings.ToLower(name)]; ok {
+		return p, nil
+	}
+	return nil, ErrUnknownProvider
+}
+
+// Names mengembalikan nama semua provider yang dikonfigurasi, terurut
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,148 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"kanban/config"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algoritma hash password yang didukung
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrUnknownHash dikembalikan jika hash tersimpan tidak dikenali formatnya
+var ErrUnknownHash = errors.New("format hash password tidak dikenal")
+
+// Argon2Params adalah parameter argon2id; disimpan di dalam hash (format PHC)
+// sehingga perubahan parameter tidak merusak hash lama
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Hasher membuat dan memverifikasi hash password sesuai konfigurasi saat ini
+type Hasher struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// FromEnv membaca PASSWORD_HASH_ALGORITHM, BCRYPT_COST dan ARGON2_*
+func FromEnv() Hasher {
+	return Hasher{
+		Algorithm:  strings.ToLower(config.GetEnv("PASSWORD_HASH_ALGORITHM", AlgorithmBcrypt)),
+		BcryptCost: config.GetEnvInt("BCRYPT_COST", 10),
+		Argon2: Argon2Params{
+			Memory:      uint32(config.GetEnvInt("ARGON2_MEMORY_KIB", 64*1024)),
+			Iterations:  uint32(config.GetEnvInt("ARGON2_ITERATIONS", 3)),
+			Parallelism: uint8(config.GetEnvInt("ARGON2_PARALLELISM", 4)),
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	}
+}
+
+// Hash membuat hash password dengan konfigurasi dari environment
+func Hash(plain string) (string, error) {
+	return FromEnv().Hash(plain)
+}
+
+// Verify mencocokkan password dengan hash tersimpan memakai konfigurasi dari environment
+func Verify(plain, encoded string) (ok, rehash bool, err error) {
+	return FromEnv().Verify(plain, encoded)
+}
+
+// Hash membuat hash password dengan algoritma yang dikonfigurasi
+func (h Hasher) Hash(plain string) (string, error) {
+	switch h.Algorithm {
+	case AlgorithmArgon2id:
+		return h.hashArgon2id(plain)
+	case AlgorithmBcrypt, "":
+		hashed, err := bcrypt.GenerateFromPassword([]byte(plain), h.BcryptCost)
+		return string(hashed), err
+	}
+	return "", fmt.Errorf("PASSWORD_HASH_ALGORITHM tidak dikenal: %s", h.Algorithm)
+}
+
+// Verify mencocokkan password dengan hash tersimpan. rehash bernilai true jika
+// password cocok tetapi hash dibuat dengan algoritma atau parameter lama,
+// sehingga pemanggil sebaiknya menyimpan ulang hash baru.
+func (h Hasher) Verify(plain, encoded string) (ok, rehash bool, err error) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, false, err
+		}
+		computed := argon2.IDKey([]byte(plain), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false, nil
+		}
+		current := h.Argon2
+		rehash = h.Algorithm != AlgorithmArgon2id ||
+			params.Memory != current.Memory || params.Iterations != current.Iterations || params.Parallelism != current.Parallelism
+		return true, rehash, nil
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, false, ErrUnknownHash
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(plain)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	rehash = (h.Algorithm != AlgorithmBcrypt && h.Algorithm != "") || cost != h.BcryptCost
+	return true, rehash, nil
+}
+
+// hashArgon2id menghasilkan hash format PHC: $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func (h Hasher) hashArgon2id(plain string) (string, error) {
+	p := h.Argon2
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHash
+	}
+	return params, salt, key, nil
+}
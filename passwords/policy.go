@@ -0,0 +1,126 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"kanban/config"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation adalah aturan policy yang dilanggar, dengan kode yang sama seperti
+// FieldError.Code (misalnya min, passworddigit, breached)
+type Violation struct {
+	Code  string
+	Param string
+}
+
+// Policy adalah aturan password untuk register, ganti password dan reset password
+type Policy struct {
+	MinLength     int // dalam karakter
+	MaxLength     int // dalam byte; bcrypt hanya memakai 72 byte pertama
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	BreachDir     string // kosong berarti cek breached password dimatikan
+}
+
+// PolicyFromEnv membaca PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH,
+// PASSWORD_REQUIRE_* dan PASSWORD_BREACH_DIR
+func PolicyFromEnv() Policy {
+	return Policy{
+		MinLength:     config.GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:     config.GetEnvInt("PASSWORD_MAX_LENGTH", 72),
+		RequireUpper:  config.GetEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		RequireLower:  config.GetEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		RequireDigit:  config.GetEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol: config.GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		BreachDir:     os.Getenv("PASSWORD_BREACH_DIR"),
+	}
+}
+
+// Check mengembalikan semua aturan yang dilanggar. Cek breached password hanya
+// dilakukan jika aturan lain sudah terpenuhi.
+func (p Policy) Check(plain string) ([]Violation, error) {
+	var violations []Violation
+	if p.MinLength > 0 && utf8.RuneCountInString(plain) < p.MinLength {
+		violations = append(violations, Violation{Code: "min", Param: strconv.Itoa(p.MinLength)})
+	}
+	if p.MaxLength > 0 && len(plain) > p.MaxLength {
+		violations = append(violations, Violation{Code: "max", Param: strconv.Itoa(p.MaxLength)})
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, Violation{Code: "passwordupper"})
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, Violation{Code: "passwordlower"})
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, Violation{Code: "passworddigit"})
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, Violation{Code: "passwordsymbol"})
+	}
+	if len(violations) > 0 || p.BreachDir == "" {
+		return violations, nil
+	}
+
+	breached, err := Breached(p.BreachDir, plain)
+	if err != nil {
+		return nil, err
+	}
+	if breached {
+		violations = append(violations, Violation{Code: "breached"})
+	}
+	return violations, nil
+}
+
+// Breached mencari password di daftar bocor lokal dengan pola k-anonymity seperti
+// range API Have I Been Pwned: dir berisi satu file per 5 karakter awal hash SHA-1
+// (huruf besar, contoh 5BAA6), tiap baris berformat SUFFIX:COUNT. Hanya file untuk
+// prefix password yang dibaca.
+func Breached(dir, plain string) (bool, error) {
+	sum := sha1.Sum([]byte(plain))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	file, err := os.Open(filepath.Join(dir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -1,7 +1,9 @@
 package routes
 
 import (
+	"kanban/config"
 	"kanban/controllers"
+	"kanban/metrics"
 	"kanban/middlewares"
 
 	"github.com/gin-gonic/gin"
@@ -10,22 +12,84 @@ import (
 )
 
 func RegisterRoutes(r *gin.Engine) {
+	// Dipasang sebelum route apa pun agar semua route ikut tercatat
+	r.Use(middlewares.Metrics())
+	r.NoRoute(middlewares.NoRoute)
+
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Health check untuk orchestrator
+	r.GET("/healthz", controllers.Healthz)
+	r.GET("/readyz", controllers.Readyz)
+
+	// Prometheus metrics, hanya untuk scraper dengan METRICS_TOKEN
+	r.GET("/metrics", middlewares.RequireMetricsToken(config.GetEnv("METRICS_TOKEN", "")), gin.WrapH(metrics.Handler()))
+
 	// Authentication
 	r.POST("/register", controllers.Register)
 	r.POST("/login", controllers.Login)
+	r.POST("/login/2fa", controllers.LoginTwoFactor)
+	r.POST("/password/forgot", controllers.ForgotPassword)
+	r.POST("/password/reset", controllers.ResetPassword)
+	r.POST("/email/verify", controllers.VerifyEmail)
+
+	// Single sign-on (OIDC)
+	r.GET("/auth/oidc/providers", controllers.GetOIDCProviders)
+	r.GET("/auth/oidc/:provider/login", controllers.OIDCLogin)
+	r.GET("/auth/oidc/:provider/callback", controllers.OIDCCallback)
 
 	auth := r.Group("/")
-	auth.Use(middlewares.AuthMiddleware())
+	auth.Use(middlewares.AuthMiddleware(), middlewares.TenantScope())
 	{
+		// User yang sedang login
+		auth.GET("/me", controllers.GetMe)
+		auth.PATCH("/me", controllers.UpdateMe)
+		auth.PUT("/me/password", controllers.ChangePassword)
+		auth.POST("/me/email/verify", controllers.SendEmailVerification)
+		auth.GET("/me/tasks", controllers.GetMyTasks)
+		auth.POST("/me/2fa/setup", controllers.SetupTwoFactor)
+		auth.POST("/me/2fa/enable", controllers.EnableTwoFactor)
+		auth.POST("/me/2fa/disable", controllers.DisableTwoFactor)
+		auth.POST("/me/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+		auth.GET("/me/identities", controllers.GetMyIdentities)
+		auth.POST("/me/identities/:provider", controllers.LinkMyIdentity)
+		auth.DELETE("/me/identities/:id", controllers.UnlinkMyIdentity)
+		auth.GET("/me/tokens", controllers.GetMyTokens)
+		auth.POST("/me/tokens", controllers.CreateMyToken)
+		auth.DELETE("/me/tokens/:id", controllers.RevokeMyToken)
+
+		// Organisasi
+		auth.POST("/organizations", controllers.CreateOrganization)
+		auth.GET("/organizations", controllers.GetMyOrganizations)
+		auth.GET("/organizations/:id", controllers.GetOrganization)
+		auth.GET("/organizations/:id/members", controllers.GetOrganizationMembers)
+		auth.PUT("/organizations/:id/members/:user_id", controllers.UpdateOrganizationMember)
+		auth.DELETE("/organizations/:id/members/:user_id", controllers.RemoveOrganizationMember)
+		auth.POST("/organizations/:id/invitations", controllers.CreateOrganizationInvitation)
+		auth.GET("/organizations/:id/invitations", controllers.GetOrganizationInvitations)
+		auth.DELETE("/organizations/:id/invitations/:invitation_id", controllers.RevokeOrganizationInvitation)
+		auth.POST("/invitations/accept", controllers.AcceptOrganizationInvitation)
+
 		// Project
 		auth.POST("/projects", controllers.CreateProject)
 		auth.GET("/projects", controllers.GetAllProjects)
 		auth.GET("/projects/:id", controllers.GetProjects)
+		auth.DELETE("/projects/:id", controllers.DeleteProject)
+		auth.GET("/projects/trash", controllers.GetDeletedProjects)
+		auth.POST("/projects/:id/restore", controllers.RestoreProject)
+		auth.GET("/projects/:id/trash", controllers.GetProjectTrash)
+		auth.POST("/projects/:id/archive", controllers.ArchiveProject)
+		auth.POST("/projects/:id/unarchive", controllers.UnarchiveProject)
 		auth.POST("/projects/:id/participants", controllers.AddParticipant)
 		auth.DELETE("/projects/:id/participants/:user_id", controllers.RemoveParticipant)
+		auth.PUT("/projects/:id/two-factor", controllers.SetProjectTwoFactor)
+		auth.POST("/projects/:id/invitations", controllers.CreateProjectInvitation)
+		auth.GET("/projects/:id/invitations", controllers.GetProjectInvitations)
+		auth.DELETE("/projects/:id/invitations/:invitation_id", controllers.RevokeProjectInvitation)
+		auth.POST("/project-invitations/accept", controllers.AcceptProjectInvitation)
+		auth.GET("/projects/:id/backlog", controllers.GetBacklog)
+		auth.PUT("/projects/:id/backlog", controllers.ReorderBacklog)
 
 		// Task
 		auth.POST("/tasks", controllers.CreateTask)
@@ -33,15 +97,46 @@ func RegisterRoutes(r *gin.Engine) {
 		auth.GET("/tasks", controllers.GetAllTasks)
 		auth.GET("/tasks/:id", controllers.GetTasks)
 		auth.PUT("/tasks/:id/assign", controllers.AssignToUser)
+		auth.PUT("/tasks/:id/estimation", controllers.UpdateTaskEstimation)
 		auth.DELETE("/tasks/:id", controllers.DeleteTask)
+		auth.POST("/tasks/:id/backlog", controllers.PushToBacklog)
+		auth.POST("/tasks/:id/restore", controllers.RestoreTask)
 
 		// Sprint
 		auth.POST("/sprints", controllers.CreateSprint)
 		auth.GET("/sprints", controllers.GetAllSprints)
 		auth.GET("/sprints/:id", controllers.GetSprint)
+		auth.DELETE("/sprints/:id", controllers.DeleteSprint)
+		auth.POST("/sprints/:id/restore", controllers.RestoreSprint)
+		auth.POST("/sprints/:id/archive", controllers.ArchiveSprint)
+		auth.POST("/sprints/:id/unarchive", controllers.UnarchiveSprint)
 		auth.GET("/sprints/:id/analytics", controllers.GetSprintAnalytics)
+		auth.GET("/sprints/:id/flow", controllers.GetSprintFlow)
 		auth.PUT("/sprints/:id/status", controllers.UpdateSprintStatus)
-		auth.GET("/projects/:project_id/sprints", controllers.GetSprintsByProject)
+		auth.POST("/sprints/:id/start", controllers.StartSprint)
+		auth.POST("/sprints/:id/complete", controllers.CompleteSprint)
+		auth.POST("/sprints/:id/tasks", controllers.PullIntoSprint)
+		auth.GET("/sprints/:id/capacity", controllers.GetSprintCapacity)
+		auth.PUT("/sprints/:id/capacity", controllers.SetSprintCapacity)
+		auth.GET("/sprints/:id/planning", controllers.GetSprintPlanning)
+		auth.GET("/projects/:id/sprints", controllers.GetSprintsByProject)
+		auth.GET("/projects/:id/velocity", controllers.GetProjectVelocity)
+		auth.GET("/projects/:id/flow", controllers.GetProjectFlow)
+		auth.GET("/projects/:id/workload", controllers.GetProjectWorkload)
+	}
+
+	// Administrasi
+	admin := r.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(), middlewares.RequireAdmin())
+	{
+		admin.GET("/users", controllers.GetAdminUsers)
+		admin.POST("/users/:id/disable", controllers.DisableUser)
+		admin.POST("/users/:id/enable", controllers.EnableUser)
+		admin.PUT("/users/:id/admin", controllers.SetUserAdmin)
+		admin.DELETE("/users/:id", controllers.DeleteUser)
+		admin.POST("/users/:id/unlock", controllers.UnlockUser)
+		admin.PUT("/projects/:id/owner", controllers.TransferProjectOwner)
+		admin.GET("/stats", controllers.GetAdminStats)
 	}
 
 }
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName dipakai sebagai service.name di setiap span
+const ServiceName = "kanban-api"
+
+// Enabled bernilai true jika OTEL_TRACES_EXPORTER diisi "stdout" atau "otlp"
+func Enabled() bool {
+	switch exporterName() {
+	case "stdout", "otlp":
+		return true
+	}
+	return false
+}
+
+func exporterName() string {
+	return strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER"))
+}
+
+// Setup memasang TracerProvider global sesuai OTEL_TRACES_EXPORTER.
+// Endpoint OTLP dibaca dari variabel standar OTEL_EXPORTER_OTLP_ENDPOINT.
+// Fungsi yang dikembalikan harus dipanggil saat shutdown untuk mem-flush span.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch exporterName() {
+	case "stdout":
+		// Span ditulis ke stderr satu baris per span agar tidak tercampur dengan log JSON di stdout
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("OTEL_TRACES_EXPORTER tidak dikenal: %s", exporterName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"kanban/models"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var once sync.Once
+
+// Setup mendaftarkan validator custom ke validator milik gin. Aman dipanggil berkali-kali.
+func Setup() {
+	once.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		// Pakai nama field JSON di error, bukan nama field Go
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		v.RegisterValidation("taskstatus", oneOf(models.TaskStatuses))
+		v.RegisterValidation("sprintstatus", oneOf(models.SprintStatuses))
+		v.RegisterValidation("estimationtype", oneOf(models.EstimationTypes))
+		v.RegisterValidation("tokenscope", oneOf(models.TokenScopes))
+		v.RegisterValidation("orgrole", oneOf(models.OrgRoles))
+		v.RegisterValidation("projectrole", oneOf(models.ProjectRoles))
+		v.RegisterValidation("maxrange", maxRange)
+	})
+}
+
+func oneOf(values []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return slices.Contains(values, fl.Field().String())
+	}
+}
+
+// maxRange memastikan field tanggal paling lama N hari (parameter tag) setelah field
+// StartDate di struct yang sama, contoh maxrange=366
+func maxRange(fl validator.FieldLevel) bool {
+	days, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	end, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	start, ok := fl.Parent().FieldByName("StartDate").Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	return !end.After(start.AddDate(0, 0, days))
+}